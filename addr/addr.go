@@ -18,7 +18,7 @@ var (
 func InnerIP() string {
 	innerIPOnce.Do(func() {
 		ip, err := Extract("")
-		if err != nil {
+		if err == nil {
 			innerIP = ip
 		}
 	})
@@ -72,5 +72,19 @@ func TestExtractHostPort(t *testing.T) {
 }
 
 func TestInnerIP(t *testing.T) {
-	t.Log(addr.InnerIP())
+	// 能解析到 IP 时 InnerIP 返回同一个地址，并且多次调用结果相同
+	expect, err := addr.Extract("")
+	if err != nil {
+		t.Skip("no IP address found:", err)
+	}
+	ip := addr.InnerIP()
+	if ip == "" || net.ParseIP(ip) == nil {
+		t.Fatalf("invalid inner IP %q", ip)
+	}
+	if ip != expect {
+		t.Fatalf("expected %s got %s", expect, ip)
+	}
+	if again := addr.InnerIP(); again != ip {
+		t.Fatalf("expected %s got %s", ip, again)
+	}
 }
@@ -0,0 +1,121 @@
+// halolog 查询 logger 输出的 json 日志文件
+//
+//	halolog -since 1h -level warn -grep timeout -field uid=100 logs/app.log
+//	halolog -f -level error logs/app.log
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/fengjx/go-halo/logger"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
+func (f fieldFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("invalid field %q, want key=value", s)
+	}
+	f[k] = v
+	return nil
+}
+
+func main() {
+	var (
+		since  = flag.Duration("since", 0, "只显示最近一段时间的日志，如 30m、2h")
+		start  = flag.String("start", "", "开始时间，格式: "+timeLayout)
+		end    = flag.String("end", "", "结束时间，格式: "+timeLayout)
+		level  = flag.String("level", "", "最低日志级别: debug, info, warn, error")
+		grep   = flag.String("grep", "", "日志内容包含的字符串")
+		limit  = flag.Int("n", 0, "只显示最后 n 条")
+		follow = flag.Bool("f", false, "持续输出新的日志")
+		layout = flag.String("time-layout", "2006-01-02 15:04:05.000", "日志中时间字段的格式")
+		fields = fieldFlags{}
+	)
+	flag.Var(fields, "field", "字段值相等，格式: key=value，可以指定多个")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: halolog [flags] logfile\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	q := &logger.Query{
+		Message: *grep,
+		Fields:  fields,
+	}
+	var err error
+	if *since > 0 {
+		q.Start = time.Now().Add(-*since)
+	}
+	if *start != "" {
+		if q.Start, err = time.ParseInLocation(timeLayout, *start, time.Local); err != nil {
+			fatal(err)
+		}
+	}
+	if *end != "" {
+		if q.End, err = time.ParseInLocation(timeLayout, *end, time.Local); err != nil {
+			fatal(err)
+		}
+	}
+	if *level != "" {
+		var min logger.Level
+		if err = min.UnmarshalText([]byte(*level)); err != nil {
+			fatal(err)
+		}
+		for lv := min; lv <= logger.FatalLevel; lv++ {
+			q.Levels = append(q.Levels, lv)
+		}
+	}
+
+	r := logger.NewReader(flag.Arg(0)).SetTimeLayout(*layout, time.Local)
+	if *follow {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = r.Follow(ctx, q, func(rec *logger.LogRecord) bool {
+			fmt.Println(rec.Raw)
+			return true
+		})
+		if err != nil && err != context.Canceled {
+			fatal(err)
+		}
+		return
+	}
+
+	// 保留最后 n 条
+	var lines []string
+	err = r.Scan(q, func(rec *logger.LogRecord) bool {
+		lines = append(lines, rec.Raw)
+		if *limit > 0 && len(lines) > *limit {
+			lines = lines[1:]
+		}
+		return true
+	})
+	if err != nil {
+		fatal(err)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "halolog:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FullPolicy 异步队列满时的处理策略
+type FullPolicy int
+
+const (
+	// FullPolicyBlock 阻塞等待，不丢日志
+	FullPolicyBlock FullPolicy = iota
+	// FullPolicyDropLow 丢弃 Debug/Info 级别日志，Warn 及以上级别阻塞等待
+	FullPolicyDropLow
+	// FullPolicyDropOldest 丢弃队列中最早的日志
+	FullPolicyDropOldest
+)
+
+const defaultAsyncBufferSize = 8192
+
+// AsyncOptions 异步写入配置
+type AsyncOptions struct {
+	BufferSize int        // 队列最大日志条数，默认: 8192
+	FullPolicy FullPolicy // 队列满时的处理策略，默认: FullPolicyBlock
+}
+
+// levelWriter 需要日志级别的输出，例如异步队列按级别丢弃、syslog 按级别设置 severity
+type levelWriter interface {
+	WriteLevel(level Level, p []byte) error
+	Sync() error
+}
+
+// syncerLevelWriter 忽略日志级别，直接写入 zapcore.WriteSyncer
+type syncerLevelWriter struct {
+	zapcore.WriteSyncer
+}
+
+func (w syncerLevelWriter) WriteLevel(_ Level, p []byte) error {
+	_, err := w.Write(p)
+	return err
+}
+
+type asyncEntry struct {
+	level Level
+	data  []byte
+}
+
+// asyncWriter 有界队列，后台协程负责写入
+type asyncWriter struct {
+	out     levelWriter
+	size    int
+	policy  FullPolicy
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []asyncEntry
+	writing bool
+	closed  bool
+	dropped uint64
+}
+
+func newAsyncWriter(out levelWriter, opt *AsyncOptions) *asyncWriter {
+	size := opt.BufferSize
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+	w := &asyncWriter{
+		out:    out,
+		size:   size,
+		policy: opt.FullPolicy,
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+// WriteLevel 写入队列，队列满时按 FullPolicy 处理
+func (w *asyncWriter) WriteLevel(level Level, data []byte) error {
+	w.push(level, data)
+	return nil
+}
+
+func (w *asyncWriter) push(level Level, data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) >= w.size || w.closed {
+		if w.closed {
+			// 关闭后直接同步写入，避免丢失日志，等待队列空间时被关闭也需要重新判断
+			_ = w.out.WriteLevel(level, data)
+			return
+		}
+		switch {
+		case w.policy == FullPolicyDropOldest:
+			w.queue = w.queue[1:]
+			atomic.AddUint64(&w.dropped, 1)
+		case w.policy == FullPolicyDropLow && level <= InfoLevel:
+			atomic.AddUint64(&w.dropped, 1)
+			return
+		default:
+			w.cond.Wait()
+		}
+	}
+	w.queue = append(w.queue, asyncEntry{level: level, data: data})
+	w.cond.Broadcast()
+}
+
+func (w *asyncWriter) run() {
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch := w.queue
+		w.queue = make([]asyncEntry, 0, len(batch))
+		w.writing = true
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		for _, e := range batch {
+			// 后台写入失败无法返回给调用方，直接忽略
+			_ = w.out.WriteLevel(e.level, e.data)
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// Sync 同 Flush
+func (w *asyncWriter) Sync() error {
+	return w.Flush()
+}
+
+// Flush 等待队列中的日志全部写入
+func (w *asyncWriter) Flush() error {
+	w.mu.Lock()
+	for len(w.queue) > 0 || w.writing {
+		w.cond.Wait()
+	}
+	w.mu.Unlock()
+	return w.out.Sync()
+}
+
+// Close 写入队列中剩余的日志后退出后台协程
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	return w.Flush()
+}
+
+// Dropped 返回丢弃的日志条数
+func (w *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// levelCore 在调用方协程完成编码后交给 levelWriter，避免字段在后台协程中读取产生竞争
+type levelCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   levelWriter
+}
+
+func newLevelCore(enc zapcore.Encoder, w levelWriter, enab zapcore.LevelEnabler) zapcore.Core {
+	return &levelCore{
+		LevelEnabler: enab,
+		enc:          enc,
+		w:            w,
+	}
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	return &levelCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          enc,
+		w:            c.w,
+	}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *levelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	buf.Free()
+	if err = c.w.WriteLevel(ent.Level, data); err != nil {
+		return err
+	}
+	if ent.Level > ErrorLevel {
+		// Panic/Fatal 之前确保日志落盘
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *levelCore) Sync() error {
+	return c.w.Sync()
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// slowWriter 模拟磁盘写入延迟
+type slowWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func (w *slowWriter) lines() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Count(w.buf.String(), "\n")
+}
+
+func TestAsyncBlock(t *testing.T) {
+	w := &slowWriter{delay: time.Millisecond}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: w}},
+		Async: &AsyncOptions{BufferSize: 10},
+	})
+	for i := 0; i < 100; i++ {
+		log.Infof("block %d", i)
+	}
+	log.Flush()
+	assert.Equal(t, 100, w.lines())
+	assert.Equal(t, uint64(0), AsyncDropped(log))
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	w := &slowWriter{delay: time.Millisecond * 5}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: w}},
+		Async: &AsyncOptions{BufferSize: 10, FullPolicy: FullPolicyDropOldest},
+	})
+	for i := 0; i < 100; i++ {
+		log.Infof("drop oldest %d", i)
+	}
+	log.Flush()
+	dropped := AsyncDropped(log)
+	assert.True(t, dropped > 0)
+	assert.Equal(t, 100, w.lines()+int(dropped))
+	// 最新的日志一定会保留
+	assert.Contains(t, w.String(), "drop oldest 99")
+}
+
+func TestAsyncDropLow(t *testing.T) {
+	w := &slowWriter{delay: time.Millisecond * 5}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: w}},
+		Async: &AsyncOptions{BufferSize: 10, FullPolicy: FullPolicyDropLow},
+	}).With()
+	for i := 0; i < 50; i++ {
+		log.Infof("info %d", i)
+		log.Warnf("warn %d", i)
+	}
+	log.Flush()
+	assert.True(t, AsyncDropped(log) > 0)
+	assert.Equal(t, 50, strings.Count(w.String(), `"level":"warn"`))
+}
+
+func TestAsyncCloseWhileBlocked(t *testing.T) {
+	w := &slowWriter{delay: time.Millisecond * 20}
+	aw := newAsyncWriter(syncerLevelWriter{WriteSyncer: zapcore.AddSync(w)}, &AsyncOptions{BufferSize: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = aw.WriteLevel(InfoLevel, []byte("line\n"))
+		}()
+	}
+	time.Sleep(time.Millisecond * 5)
+	// 等待队列空间的写入在关闭后同步写入，不会留在没有协程处理的队列中
+	assert.NoError(t, aw.Close())
+	wg.Wait()
+	assert.Equal(t, 5, w.lines())
+	aw.mu.Lock()
+	assert.Empty(t, aw.queue)
+	aw.mu.Unlock()
+}
@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression 切割文件压缩格式
+type Compression string
+
+const (
+	// CompressNone 不压缩
+	CompressNone Compression = ""
+	// CompressGzip gzip 压缩，文件名增加 .gz 后缀
+	CompressGzip Compression = "gzip"
+	// CompressZstd zstd 压缩，文件名增加 .zst 后缀
+	CompressZstd Compression = "zstd"
+)
+
+const (
+	gzipExt = ".gz"
+	zstdExt = ".zst"
+)
+
+// backupCleaner 后台压缩和清理切割文件，不阻塞日志写入
+type backupCleaner struct {
+	name         string
+	compress     Compression
+	maxBackups   int
+	maxDays      int
+	maxTotalSize int64
+	ch           chan time.Time
+	done         chan struct{}
+	mu           sync.Mutex
+	started      bool
+	closed       bool
+}
+
+func newBackupCleaner(name string, opt *Options) *backupCleaner {
+	return &backupCleaner{
+		name:         name,
+		compress:     opt.Compress,
+		maxBackups:   opt.MaxBackups,
+		maxDays:      opt.MaxDays,
+		maxTotalSize: int64(opt.MaxTotalSizeMB) * 1024 * 1024,
+		ch:           make(chan time.Time, 1),
+		done:         make(chan struct{}),
+	}
+}
+
+// trigger 通知后台协程执行清理，已有待执行的清理时直接返回
+func (c *backupCleaner) trigger(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if !c.started {
+		c.started = true
+		go c.run()
+	}
+	select {
+	case c.ch <- now:
+	default:
+	}
+}
+
+func (c *backupCleaner) run() {
+	defer close(c.done)
+	for now := range c.ch {
+		c.clean(now)
+	}
+}
+
+// close 停止后台协程，等待正在执行的清理完成
+func (c *backupCleaner) close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	started := c.started
+	close(c.ch)
+	c.mu.Unlock()
+	if started {
+		<-c.done
+	}
+}
+
+// clean 压缩未压缩的切割文件，然后按 maxBackups、maxDays、maxTotalSize 删除最早的文件
+func (c *backupCleaner) clean(now time.Time) {
+	dir := filepath.Dir(c.name)
+	if c.compress != CompressNone {
+		for _, info := range listBackups(c.name) {
+			if isCompressed(info.Name()) {
+				continue
+			}
+			_ = compressFile(filepath.Join(dir, info.Name()), c.compress)
+		}
+	}
+
+	cutoff := now.Add(-time.Duration(c.maxDays) * time.Hour * 24)
+	var total int64
+	for i, info := range listBackups(c.name) {
+		total += info.Size()
+		if (c.maxBackups > 0 && i >= c.maxBackups) ||
+			(c.maxDays > 0 && info.ModTime().Before(cutoff)) ||
+			(c.maxTotalSize > 0 && total > c.maxTotalSize) {
+			_ = os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+}
+
+// listBackups 返回 name 对应的切割文件（包括压缩文件），按修改时间倒序
+func listBackups(name string) []os.FileInfo {
+	dir := filepath.Dir(name)
+	filename := filepath.Base(name)
+	ext := filepath.Ext(filename)
+	prefix := filename[:len(filename)-len(ext)] + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		n := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(n, prefix) || len(n) <= len(prefix) {
+			continue
+		}
+		// 切割文件名中的时间以数字开头，避免误删 app-error.log 这类文件
+		if c := n[len(prefix)]; c < '0' || c > '9' {
+			continue
+		}
+		// 跳过压缩过程中的临时文件
+		if strings.HasSuffix(n, ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+	return backups
+}
+
+func isCompressed(name string) bool {
+	return strings.HasSuffix(name, gzipExt) || strings.HasSuffix(name, zstdExt)
+}
+
+// compressFile 压缩文件，成功后删除原文件，压缩文件保留原文件的修改时间
+func compressFile(src string, compression Compression) (err error) {
+	var ext string
+	switch compression {
+	case CompressGzip:
+		ext = gzipExt
+	case CompressZstd:
+		ext = zstdExt
+	default:
+		return nil
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	dst := src + ext
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	var cw io.WriteCloser
+	if compression == CompressGzip {
+		cw = gzip.NewWriter(out)
+	} else {
+		cw, err = zstd.NewWriter(out)
+		if err != nil {
+			_ = out.Close()
+			return err
+		}
+	}
+	if _, err = io.Copy(cw, in); err != nil {
+		_ = cw.Close()
+		_ = out.Close()
+		return err
+	}
+	if err = cw.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressFile(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("hello compress\n", 100)
+	for _, c := range []Compression{CompressGzip, CompressZstd} {
+		src := filepath.Join(dir, "app-"+string(c)+".log")
+		assert.NoError(t, os.WriteFile(src, []byte(content), 0644))
+		assert.NoError(t, compressFile(src, c))
+		_, err := os.Stat(src)
+		assert.True(t, os.IsNotExist(err))
+
+		var r io.Reader
+		if c == CompressGzip {
+			f, err := os.Open(src + gzipExt)
+			assert.NoError(t, err)
+			r, err = gzip.NewReader(f)
+			assert.NoError(t, err)
+			defer f.Close()
+		} else {
+			f, err := os.Open(src + zstdExt)
+			assert.NoError(t, err)
+			zr, err := zstd.NewReader(f)
+			assert.NoError(t, err)
+			r = zr
+			defer f.Close()
+			defer zr.Close()
+		}
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+}
+
+func TestCleanTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	now := time.Now()
+	// 每个文件 1MB，越往后越早
+	for i := 0; i < 5; i++ {
+		backup := filepath.Join(dir, fmt.Sprintf("app-2023010%d.log", i+1))
+		assert.NoError(t, os.WriteFile(backup, make([]byte, 1024*1024), 0644))
+		mtime := now.Add(-time.Hour * time.Duration(i+1))
+		assert.NoError(t, os.Chtimes(backup, mtime, mtime))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app-error.log"), []byte("x"), 0644))
+
+	c := newBackupCleaner(name, &Options{MaxTotalSizeMB: 3})
+	c.clean(now)
+	backups := listBackups(name)
+	assert.Len(t, backups, 3)
+	for _, info := range backups {
+		assert.NotContains(t, []string{"app-20230104.log", "app-20230105.log"}, info.Name())
+	}
+	_, err := os.Stat(filepath.Join(dir, "app-error.log"))
+	assert.NoError(t, err)
+}
+
+func TestRotateCompress(t *testing.T) {
+	currentTime = fakeTime
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	dir := t.TempDir()
+	logFilepath := filepath.Join(dir, "compress.log")
+	log := New(&Options{
+		LogFile:  logFilepath,
+		Compress: CompressGzip,
+	})
+	log.Info("day 1")
+	backup := filepath.Join(dir, "compress-"+fakeTime().Format(backupDayFormat)+".log"+gzipExt)
+	makeFakeTime(time.Hour * 24)
+	log.Info("day 2")
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(backup)
+		return err == nil
+	}, time.Second*3, time.Millisecond*10)
+}
+
+func TestRotateSize(t *testing.T) {
+	dir := t.TempDir()
+	logFilepath := filepath.Join(dir, "size.log")
+	log := New(&Options{
+		LogFile:   logFilepath,
+		MaxSizeMB: 1,
+	})
+	msg := strings.Repeat("a", 1024)
+	for i := 0; i < 1500; i++ {
+		log.Info(msg)
+	}
+	_, err := os.Stat(filepath.Join(dir, "size-"+currentTime().Format(backupDayFormat)+".log"))
+	assert.NoError(t, err)
+	info, err := os.Stat(logFilepath)
+	assert.NoError(t, err)
+	assert.True(t, info.Size() < 1024*1024)
+}
@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fengjx/go-halo/json"
+)
+
+// 支持的环境变量，优先级高于配置文件
+const (
+	EnvLogLevel      = "HALO_LOG_LEVEL"
+	EnvLogFile       = "HALO_LOG_FILE"
+	EnvLogMaxSizeMB  = "HALO_LOG_MAX_SIZE_MB"
+	EnvLogMaxBackups = "HALO_LOG_MAX_BACKUPS"
+	EnvLogMaxDays    = "HALO_LOG_MAX_DAYS"
+	EnvLogThin       = "HALO_LOG_THIN"
+)
+
+const defaultWatchInterval = time.Second * 5
+
+// Config 日志配置文件，支持 yaml 和 json 格式，字段含义与 Options 一致
+type Config struct {
+	Level            string            `json:"level" yaml:"level"`
+	LogFile          string            `json:"log_file" yaml:"log_file"`
+	MaxSizeMB        int               `json:"max_size_mb" yaml:"max_size_mb"`
+	MaxBackups       int               `json:"max_backups" yaml:"max_backups"`
+	MaxDays          int               `json:"max_days" yaml:"max_days"`
+	Thin             bool              `json:"thin" yaml:"thin"`
+	RotateInterval   string            `json:"rotate_interval" yaml:"rotate_interval"` // 例如 1h、10m
+	BackupTimeFormat string            `json:"backup_time_format" yaml:"backup_time_format"`
+	UTC              bool              `json:"utc" yaml:"utc"`
+	Compress         string            `json:"compress" yaml:"compress"` // gzip、zstd
+	MaxTotalSizeMB   int               `json:"max_total_size_mb" yaml:"max_total_size_mb"`
+	Sinks            []SinkConfig      `json:"sinks" yaml:"sinks"`
+	Async            *AsyncConfig      `json:"async" yaml:"async"`
+	Sampler          *SamplerConfig    `json:"sampler" yaml:"sampler"`
+	Redact           *RedactConfig     `json:"redact" yaml:"redact"`
+	Modules          map[string]string `json:"modules" yaml:"modules"` // 模块日志级别，参考 SetModuleLevel
+	AddCaller        bool              `json:"add_caller" yaml:"add_caller"`
+	CallerSkip       int               `json:"caller_skip" yaml:"caller_skip"`
+	AddGoID          bool              `json:"add_goid" yaml:"add_goid"`
+	AddHostname      bool              `json:"add_hostname" yaml:"add_hostname"`
+	AddInnerIP       bool              `json:"add_inner_ip" yaml:"add_inner_ip"`
+	StacktraceLevel  string            `json:"stacktrace_level" yaml:"stacktrace_level"` // 为空时默认 panic
+}
+
+// SinkConfig 对应 Sink，设置 network 时输出到远程地址，log_file 和 network 都为空时输出到 stdout
+type SinkConfig struct {
+	Level    string `json:"level" yaml:"level"`
+	Encoding string `json:"encoding" yaml:"encoding"` // json、console
+	LogFile  string `json:"log_file" yaml:"log_file"`
+	Thin     bool   `json:"thin" yaml:"thin"`
+	Network  string `json:"network" yaml:"network"` // tcp、udp、unix、unixgram
+	Addr     string `json:"addr" yaml:"addr"`
+	Format   string `json:"format" yaml:"format"` // rfc5424，为空时原样发送
+}
+
+// AsyncConfig 对应 AsyncOptions
+type AsyncConfig struct {
+	BufferSize int    `json:"buffer_size" yaml:"buffer_size"`
+	FullPolicy string `json:"full_policy" yaml:"full_policy"` // block、drop_low、drop_oldest
+}
+
+// SamplerConfig 对应 SamplerOptions
+type SamplerConfig struct {
+	Tick        string         `json:"tick" yaml:"tick"` // 例如 1s
+	First       int            `json:"first" yaml:"first"`
+	Thereafter  int            `json:"thereafter" yaml:"thereafter"`
+	LevelLimits map[string]int `json:"level_limits" yaml:"level_limits"`
+}
+
+// RedactConfig 对应 RedactOptions，patterns 为正则表达式
+type RedactConfig struct {
+	Keys     []string `json:"keys" yaml:"keys"`
+	Patterns []string `json:"patterns" yaml:"patterns"`
+	Mask     string   `json:"mask" yaml:"mask"`
+}
+
+// LoadConfig 读取配置文件，根据扩展名解析 yaml 或 json，然后使用环境变量覆盖
+// filename 为空时只读取环境变量
+func LoadConfig(filename string) (*Config, error) {
+	c := &Config{}
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, c)
+		case ".json":
+			err = json.FromBytes(data, c)
+		default:
+			err = fmt.Errorf("unsupported config file: %s", filename)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadEnv 使用环境变量覆盖配置，环境变量的值不合法时返回错误
+func (c *Config) LoadEnv() error {
+	if v, ok := os.LookupEnv(EnvLogLevel); ok {
+		c.Level = v
+	}
+	if v, ok := os.LookupEnv(EnvLogFile); ok {
+		c.LogFile = v
+	}
+	var err error
+	if v, ok := os.LookupEnv(EnvLogMaxSizeMB); ok {
+		if c.MaxSizeMB, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvLogMaxSizeMB, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvLogMaxBackups); ok {
+		if c.MaxBackups, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvLogMaxBackups, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvLogMaxDays); ok {
+		if c.MaxDays, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvLogMaxDays, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvLogThin); ok {
+		if c.Thin, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvLogThin, err)
+		}
+	}
+	return nil
+}
+
+// Options 转换为 Options
+func (c *Config) Options() (*Options, error) {
+	opt := &Options{
+		Level:            GetLogLevel(c.Level),
+		LogFile:          c.LogFile,
+		MaxSizeMB:        c.MaxSizeMB,
+		MaxBackups:       c.MaxBackups,
+		MaxDays:          c.MaxDays,
+		Thin:             c.Thin,
+		BackupTimeFormat: c.BackupTimeFormat,
+		UTC:              c.UTC,
+		Compress:         Compression(c.Compress),
+		MaxTotalSizeMB:   c.MaxTotalSizeMB,
+		AddCaller:        c.AddCaller,
+		CallerSkip:       c.CallerSkip,
+		AddGoID:          c.AddGoID,
+		AddHostname:      c.AddHostname,
+		AddInnerIP:       c.AddInnerIP,
+	}
+	if c.StacktraceLevel != "" {
+		level := GetLogLevel(c.StacktraceLevel)
+		opt.StacktraceLevel = &level
+	}
+	var err error
+	if c.RotateInterval != "" {
+		if opt.RotateInterval, err = time.ParseDuration(c.RotateInterval); err != nil {
+			return nil, fmt.Errorf("invalid rotate_interval: %w", err)
+		}
+	}
+	switch opt.Compress {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return nil, fmt.Errorf("invalid compress: %s", c.Compress)
+	}
+	for _, sc := range c.Sinks {
+		sink := Sink{
+			Level:    GetLogLevel(sc.Level),
+			Encoding: Encoding(sc.Encoding),
+			LogFile:  sc.LogFile,
+			Thin:     sc.Thin,
+		}
+		if sc.Network != "" {
+			sink.Remote = &RemoteOptions{
+				Network: sc.Network,
+				Addr:    sc.Addr,
+				Format:  RemoteFormat(sc.Format),
+			}
+		}
+		opt.Sinks = append(opt.Sinks, sink)
+	}
+	if c.Async != nil {
+		opt.Async = &AsyncOptions{BufferSize: c.Async.BufferSize}
+		switch c.Async.FullPolicy {
+		case "", "block":
+			opt.Async.FullPolicy = FullPolicyBlock
+		case "drop_low":
+			opt.Async.FullPolicy = FullPolicyDropLow
+		case "drop_oldest":
+			opt.Async.FullPolicy = FullPolicyDropOldest
+		default:
+			return nil, fmt.Errorf("invalid async full_policy: %s", c.Async.FullPolicy)
+		}
+	}
+	if c.Sampler != nil {
+		opt.Sampler = &SamplerOptions{
+			First:      c.Sampler.First,
+			Thereafter: c.Sampler.Thereafter,
+		}
+		if c.Sampler.Tick != "" {
+			if opt.Sampler.Tick, err = time.ParseDuration(c.Sampler.Tick); err != nil {
+				return nil, fmt.Errorf("invalid sampler tick: %w", err)
+			}
+		}
+		if len(c.Sampler.LevelLimits) > 0 {
+			opt.Sampler.LevelLimits = make(map[Level]int, len(c.Sampler.LevelLimits))
+			for lv, limit := range c.Sampler.LevelLimits {
+				opt.Sampler.LevelLimits[GetLogLevel(lv)] = limit
+			}
+		}
+	}
+	if c.Redact != nil {
+		opt.Redact = &RedactOptions{
+			Keys: c.Redact.Keys,
+			Mask: c.Redact.Mask,
+		}
+		for _, p := range c.Redact.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redact pattern: %w", err)
+			}
+			opt.Redact.Patterns = append(opt.Redact.Patterns, re)
+		}
+	}
+	return opt, nil
+}
+
+// ModuleLevels 返回模块日志级别规则表
+func (c *Config) ModuleLevels() map[string]Level {
+	rules := make(map[string]Level, len(c.Modules))
+	for pattern, level := range c.Modules {
+		rules[pattern] = GetLogLevel(level)
+	}
+	return rules
+}
+
+// NewFromConfig 读取配置文件和环境变量创建 Logger，参考 LoadConfig
+// 配置了 modules 时替换全局的模块日志级别规则表
+func NewFromConfig(filename string, opts ...zap.Option) (Logger, error) {
+	c, err := LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	opt, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	if c.Modules != nil {
+		SetModuleLevels(c.ModuleLevels())
+	}
+	return New(opt, opts...), nil
+}
+
+// Watch 定时检查配置文件，文件变化后通过 Reload 应用到 Logger，返回停止监听的函数
+// interval <= 0 时默认 5s 检查一次
+func Watch(l Logger, filename string, interval time.Duration) (stop func(), err error) {
+	if lg, ok := l.(*logger); !ok || lg.root == nil {
+		return nil, ErrReloadNotSupported
+	}
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	last, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	quit := make(chan struct{})
+	go func() {
+		tk := time.NewTicker(interval)
+		defer tk.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-tk.C:
+			}
+			info, err := os.Stat(filename)
+			if err != nil || (info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+			if err = reloadFromFile(l, filename); err != nil {
+				log.Printf("log config reload err - %v \n", err)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+		})
+	}, nil
+}
+
+func reloadFromFile(l Logger, filename string) error {
+	c, err := LoadConfig(filename)
+	if err != nil {
+		return err
+	}
+	opt, err := c.Options()
+	if err != nil {
+		return err
+	}
+	if c.Modules != nil {
+		SetModuleLevels(c.ModuleLevels())
+	}
+	return Reload(l, opt)
+}
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testYamlConfig = `
+level: debug
+rotate_interval: 1h
+compress: gzip
+sinks:
+  - level: debug
+    log_file: app.log
+  - level: warn
+    encoding: console
+  - network: udp
+    addr: 127.0.0.1:514
+    format: rfc5424
+async:
+  buffer_size: 100
+  full_policy: drop_oldest
+sampler:
+  tick: 2s
+  first: 10
+  level_limits:
+    info: 100
+redact:
+  keys: [password]
+  patterns: ['1[3-9]\d{9}']
+`
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "log.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte(testYamlConfig), 0644))
+
+	t.Setenv(EnvLogLevel, "warn")
+	t.Setenv(EnvLogMaxDays, "3")
+	c, err := LoadConfig(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "warn", c.Level)
+	assert.Equal(t, 3, c.MaxDays)
+
+	opt, err := c.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, WarnLevel, opt.Level)
+	assert.Equal(t, time.Hour, opt.RotateInterval)
+	assert.Equal(t, CompressGzip, opt.Compress)
+	assert.Len(t, opt.Sinks, 3)
+	assert.Equal(t, EncodingConsole, opt.Sinks[1].Encoding)
+	assert.Equal(t, RemoteFormatRFC5424, opt.Sinks[2].Remote.Format)
+	assert.Equal(t, FullPolicyDropOldest, opt.Async.FullPolicy)
+	assert.Equal(t, time.Second*2, opt.Sampler.Tick)
+	assert.Equal(t, 100, opt.Sampler.LevelLimits[InfoLevel])
+	assert.Len(t, opt.Redact.Patterns, 1)
+
+	// 环境变量不合法时返回错误
+	t.Setenv(EnvLogMaxDays, "7d")
+	_, err = LoadConfig(filename)
+	assert.ErrorContains(t, err, EnvLogMaxDays)
+	t.Setenv(EnvLogMaxDays, "3")
+
+	jsonFile := filepath.Join(dir, "log.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"log_file":"app.log","async":{"full_policy":"unknown"}}`), 0644))
+	c, err = LoadConfig(jsonFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "app.log", c.LogFile)
+	_, err = c.Options()
+	assert.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "log.json")
+	logFile := filepath.Join(dir, "app.log")
+	newLogFile := filepath.Join(dir, "new.log")
+	assert.NoError(t, os.WriteFile(filename, []byte(`{"level":"info","log_file":"`+logFile+`"}`), 0644))
+
+	log, err := NewFromConfig(filename)
+	assert.NoError(t, err)
+	stop, err := Watch(log, filename, time.Millisecond*10)
+	assert.NoError(t, err)
+	defer stop()
+
+	withLog := log.With()
+	withLog.Debug("debug before reload")
+	withLog.Info("info before reload")
+
+	newConfig := `{"level":"debug","sinks":[{"level":"debug","log_file":"` + newLogFile + `"}]}`
+	assert.NoError(t, os.WriteFile(filename, []byte(newConfig), 0644))
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(filename, later, later))
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == DebugLevel
+	}, time.Second*3, time.Millisecond*10)
+
+	withLog.Debug("debug after reload")
+	log.Flush()
+
+	data, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "info before reload")
+	assert.NotContains(t, string(data), "debug before reload")
+	data, err = os.ReadFile(newLogFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "debug after reload")
+}
+
+func TestReloadNotSupported(t *testing.T) {
+	assert.ErrorIs(t, Reload(NewConsole(), &Options{}), ErrReloadNotSupported)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	log := New(&Options{LogFile: filepath.Join(dir, "app-0.log")})
+	log.Info("warm up")
+	log.Flush()
+	before := runtime.NumGoroutine()
+
+	// 重载的同时写日志，旧输出关闭前等待正在进行的写入完成，所有日志都能写入某个文件
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	var written atomic.Int64
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				log.Info("reload write")
+				written.Add(1)
+			}
+		}()
+	}
+	for i := 1; i <= 50; i++ {
+		assert.NoError(t, Reload(log, &Options{
+			LogFile:  filepath.Join(dir, fmt.Sprintf("app-%d.log", i)),
+			Compress: CompressGzip,
+		}))
+	}
+	close(stop)
+	wg.Wait()
+	log.Flush()
+
+	var lines int64
+	for i := 0; i <= 50; i++ {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("app-%d.log", i)))
+		if err == nil {
+			lines += int64(strings.Count(string(data), "reload write"))
+		}
+	}
+	assert.Equal(t, written.Load(), lines)
+
+	// 旧输出的清理协程随 Reload 退出
+	n := runtime.NumGoroutine()
+	for i := 0; i < 100 && n > before; i++ {
+		time.Sleep(time.Millisecond * 10)
+		n = runtime.NumGoroutine()
+	}
+	assert.LessOrEqual(t, n, before)
+}
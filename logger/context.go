@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ContextExtractor 从 context 中提取日志字段，ok 为 false 时不输出
+type ContextExtractor func(ctx context.Context) (field zap.Field, ok bool)
+
+type namedExtractor struct {
+	name string
+	fn   ContextExtractor
+}
+
+var (
+	ctxExtractors     []namedExtractor
+	ctxExtractorsLock sync.RWMutex
+)
+
+// RegisterContextKey 注册 context key，日志输出时以 name 作为字段名
+// 例如：RegisterContextKey("trace_id", traceIDKey{})
+func RegisterContextKey(name string, key any) {
+	RegisterContextExtractor(name, func(ctx context.Context) (zap.Field, bool) {
+		val := ctx.Value(key)
+		if val == nil {
+			return zap.Skip(), false
+		}
+		return zap.Any(name, val), true
+	})
+}
+
+// RegisterContextExtractor 注册自定义字段提取函数，name 相同时覆盖之前的注册
+func RegisterContextExtractor(name string, fn ContextExtractor) {
+	ctxExtractorsLock.Lock()
+	defer ctxExtractorsLock.Unlock()
+	for i, item := range ctxExtractors {
+		if item.name == name {
+			ctxExtractors[i].fn = fn
+			return
+		}
+	}
+	ctxExtractors = append(ctxExtractors, namedExtractor{name: name, fn: fn})
+}
+
+// UnregisterContextExtractor 取消注册
+func UnregisterContextExtractor(name string) {
+	ctxExtractorsLock.Lock()
+	defer ctxExtractorsLock.Unlock()
+	for i, item := range ctxExtractors {
+		if item.name == name {
+			ctxExtractors = append(ctxExtractors[:i:i], ctxExtractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// ContextFields 按注册顺序从 context 中提取日志字段，支持 context.Context 和 *halo.Context
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	ctxExtractorsLock.RLock()
+	defer ctxExtractorsLock.RUnlock()
+	var fields []zap.Field
+	for _, item := range ctxExtractors {
+		if field, ok := item.fn(ctx); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/go-halo/halo"
+)
+
+type traceIDKey struct{}
+
+func TestContextFields(t *testing.T) {
+	RegisterContextKey("trace_id", traceIDKey{})
+	RegisterContextKey("uid", "uid")
+	defer UnregisterContextExtractor("trace_id")
+	defer UnregisterContextExtractor("uid")
+
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: buf}},
+	})
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "t-001")
+	log.InfoCtx(ctx, "with trace")
+	assert.Contains(t, buf.String(), `"trace_id":"t-001"`)
+	assert.NotContains(t, buf.String(), `"uid"`)
+
+	buf.Reset()
+	hctx := halo.NewContext(ctx)
+	hctx.Set("uid", int64(1000))
+	log.WithContext(hctx).Info("with halo context")
+	assert.Contains(t, buf.String(), `"trace_id":"t-001"`)
+	assert.Contains(t, buf.String(), `"uid":1000`)
+}
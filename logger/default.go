@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	defaultLock   sync.RWMutex
+	defaultLogger Logger
+	// skipLogger 给包级别函数使用，跳过一层调用栈，保证 caller 正确
+	skipLogger Logger
+)
+
+func init() {
+	SetDefault(NewConsole())
+}
+
+// SetDefault 设置全局默认 Logger
+func SetDefault(l Logger) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultLogger = l
+	skipLogger = addCallerSkip(l, 1)
+}
+
+// Default 返回全局默认 Logger，未设置时输出到控制台
+func Default() Logger {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return defaultLogger
+}
+
+func getSkipLogger() Logger {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return skipLogger
+}
+
+func addCallerSkip(l Logger, skip int) Logger {
+	switch lg := l.(type) {
+	case *logger:
+		return lg.clone(lg.log.WithOptions(zap.AddCallerSkip(skip)))
+	case *slogLogger:
+		c := *lg
+		c.callerSkip += skip
+		return &c
+	default:
+		return l
+	}
+}
+
+// Debug 使用默认 Logger 输出日志
+func Debug(msg string, fields ...zap.Field) {
+	getSkipLogger().Debug(msg, fields...)
+}
+
+// Info 使用默认 Logger 输出日志
+func Info(msg string, fields ...zap.Field) {
+	getSkipLogger().Info(msg, fields...)
+}
+
+// Warn 使用默认 Logger 输出日志
+func Warn(msg string, fields ...zap.Field) {
+	getSkipLogger().Warn(msg, fields...)
+}
+
+// Error 使用默认 Logger 输出日志
+func Error(msg string, fields ...zap.Field) {
+	getSkipLogger().Error(msg, fields...)
+}
+
+// DPanic 使用默认 Logger 输出日志
+func DPanic(msg string, fields ...zap.Field) {
+	getSkipLogger().DPanic(msg, fields...)
+}
+
+// Panic 使用默认 Logger 输出日志，然后 panic
+func Panic(msg string, fields ...zap.Field) {
+	getSkipLogger().Panic(msg, fields...)
+}
+
+// Fatal 使用默认 Logger 输出日志，然后退出进程
+func Fatal(msg string, fields ...zap.Field) {
+	getSkipLogger().Fatal(msg, fields...)
+}
+
+// Debugf 使用默认 Logger 输出日志
+func Debugf(format string, args ...interface{}) {
+	getSkipLogger().Debugf(format, args...)
+}
+
+// Infof 使用默认 Logger 输出日志
+func Infof(format string, args ...interface{}) {
+	getSkipLogger().Infof(format, args...)
+}
+
+// Warnf 使用默认 Logger 输出日志
+func Warnf(format string, args ...interface{}) {
+	getSkipLogger().Warnf(format, args...)
+}
+
+// Errorf 使用默认 Logger 输出日志
+func Errorf(format string, args ...interface{}) {
+	getSkipLogger().Errorf(format, args...)
+}
+
+// DPanicf 使用默认 Logger 输出日志
+func DPanicf(format string, args ...interface{}) {
+	getSkipLogger().DPanicf(format, args...)
+}
+
+// Panicf 使用默认 Logger 输出日志，然后 panic
+func Panicf(format string, args ...interface{}) {
+	getSkipLogger().Panicf(format, args...)
+}
+
+// Fatalf 使用默认 Logger 输出日志，然后退出进程
+func Fatalf(format string, args ...interface{}) {
+	getSkipLogger().Fatalf(format, args...)
+}
+
+// DebugCtx 使用默认 Logger 输出日志，附带 context 中注册的字段
+func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	getSkipLogger().DebugCtx(ctx, msg, fields...)
+}
+
+// InfoCtx 使用默认 Logger 输出日志，附带 context 中注册的字段
+func InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	getSkipLogger().InfoCtx(ctx, msg, fields...)
+}
+
+// WarnCtx 使用默认 Logger 输出日志，附带 context 中注册的字段
+func WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	getSkipLogger().WarnCtx(ctx, msg, fields...)
+}
+
+// ErrorCtx 使用默认 Logger 输出日志，附带 context 中注册的字段
+func ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	getSkipLogger().ErrorCtx(ctx, msg, fields...)
+}
+
+// Flush 默认 Logger 日志刷盘
+func Flush() {
+	Default().Flush()
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/fengjx/go-halo/addr"
+	"github.com/fengjx/go-halo/halo"
+)
+
+var getHostname = sync.OnceValue(func() string {
+	hostname, _ := os.Hostname()
+	return hostname
+})
+
+// enrichOptions 根据配置返回调用位置和调用栈相关的 zap.Option，放在 New 传入的 zap.Option 之前，便于覆盖
+func enrichOptions(opt *Options) []zap.Option {
+	var options []zap.Option
+	if opt.AddCaller {
+		options = append(options, zap.AddCaller(), zap.AddCallerSkip(opt.CallerSkip))
+	}
+	if opt.StacktraceLevel != nil {
+		options = append(options, zap.AddStacktrace(*opt.StacktraceLevel))
+	}
+	return options
+}
+
+// staticFields 不会变化的字段，创建 Logger 时计算一次
+func staticFields(opt *Options) []zap.Field {
+	var fields []zap.Field
+	if opt.AddHostname {
+		fields = append(fields, zap.String("hostname", getHostname()))
+	}
+	if opt.AddInnerIP {
+		fields = append(fields, zap.String("ip", addr.InnerIP()))
+	}
+	return fields
+}
+
+// goidCore 写入时附加当前协程 ID
+type goidCore struct {
+	zapcore.Core
+}
+
+func newGoIDCore(core zapcore.Core) zapcore.Core {
+	return &goidCore{Core: core}
+}
+
+func (c *goidCore) With(fields []zapcore.Field) zapcore.Core {
+	return &goidCore{Core: c.Core.With(fields)}
+}
+
+func (c *goidCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *goidCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	all = append(all, zap.Int64("goid", halo.GetGoID()))
+	return c.Core.Write(ent, all)
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/go-halo/addr"
+	"github.com/fengjx/go-halo/halo"
+)
+
+func TestEnrich(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks:           []Sink{{Writer: buf}},
+		AddCaller:       true,
+		AddGoID:         true,
+		AddHostname:     true,
+		AddInnerIP:      true,
+		StacktraceLevel: &ErrorLevel,
+	})
+	log.Info("enrich info")
+	out := buf.String()
+	assert.Contains(t, out, `"caller":"logger/enrich_test.go:`)
+	assert.Contains(t, out, `"fn":"github.com/fengjx/go-halo/logger.TestEnrich"`)
+	assert.Contains(t, out, `"goid":`+strconv.FormatInt(halo.GetGoID(), 10))
+	assert.Contains(t, out, `"hostname":"`+getHostname()+`"`)
+	assert.Contains(t, out, `"ip":"`+addr.InnerIP()+`"`)
+	assert.NotContains(t, out, `"stacktrace"`)
+
+	buf.Reset()
+	log.With().Error("enrich error")
+	assert.Contains(t, buf.String(), `"stacktrace":"github.com/fengjx/go-halo/logger.TestEnrich`)
+
+	// 通过 slog 输出时调用位置是 slog 的调用方
+	buf.Reset()
+	slog.New(NewSlogHandler(log)).Info("enrich slog")
+	out = buf.String()
+	assert.Contains(t, out, `"caller":"logger/enrich_test.go:`)
+	assert.Contains(t, out, `"fn":"github.com/fengjx/go-halo/logger.TestEnrich"`)
+	assert.Contains(t, out, `"msg":"enrich slog"`)
+}
+
+func TestCallerSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks:      []Sink{{Writer: buf}},
+		AddCaller:  true,
+		CallerSkip: 1,
+	})
+	logWrapper(log, "wrapped")
+	assert.Contains(t, buf.String(), `"fn":"github.com/fengjx/go-halo/logger.TestCallerSkip"`)
+}
+
+func logWrapper(log Logger, msg string) {
+	log.Info(msg)
+}
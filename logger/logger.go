@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -10,11 +12,12 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
-	backupDayFormat = "20060102"
+	backupDayFormat    = "20060102"
+	backupHourFormat   = "2006010215"
+	backupMinuteFormat = "200601021504"
 )
 
 // currentTime 时间获取
@@ -25,9 +28,25 @@ type (
 	TimeEncoder = zapcore.TimeEncoder
 )
 
+// Encoding 日志编码格式
+type Encoding string
+
+const (
+	// EncodingJSON json 格式
+	EncodingJSON Encoding = "json"
+	// EncodingConsole 控制台格式，便于阅读
+	EncodingConsole Encoding = "console"
+)
+
 type Logger interface {
 	With(fields ...zap.Field) Logger
 
+	// WithContext 返回附带 context 中注册字段的 Logger，参考 RegisterContextKey
+	WithContext(ctx context.Context) Logger
+
+	// Named 返回子模块 Logger，多次调用使用 . 连接模块名，模块日志级别参考 SetModuleLevel
+	Named(name string) Logger
+
 	Debug(msg string, fields ...zap.Field)
 	Info(msg string, fields ...zap.Field)
 	Warn(msg string, fields ...zap.Field)
@@ -44,27 +63,68 @@ type Logger interface {
 	Panicf(format string, args ...interface{})
 	Fatalf(format string, args ...interface{})
 
+	DebugCtx(ctx context.Context, msg string, fields ...zap.Field)
+	InfoCtx(ctx context.Context, msg string, fields ...zap.Field)
+	WarnCtx(ctx context.Context, msg string, fields ...zap.Field)
+	ErrorCtx(ctx context.Context, msg string, fields ...zap.Field)
+
 	// Flush 日志刷盘
 	Flush()
 
 	// SetLevel 运行时修改日志级别
+	// Named 创建的 Logger 只修改自身模块的级别（等同于 SetModuleLevel），不影响父 Logger 和同级模块
+	// 级别优先级：模块精确规则 > 模块前缀规则 > 根 Logger 级别
 	SetLevel(level Level)
+
+	// GetLevel 返回当前生效的日志级别
+	GetLevel() Level
 }
 
 type logger struct {
 	atomicLevel zap.AtomicLevel
 	log         *zap.Logger
+	root        *reloadableCore // New 创建的 Logger 才有，用于 Reload
+	module      *moduleLevel    // Named 创建的 Logger 才有
 }
 
 // Options 日志配置
 type Options struct {
-	Level       Level       // 默认: InfoLevel
-	LogFile     string      // 默认: ${home}/logs/${app}
-	MaxSizeMB   int         // 默认: 12*1024, 2GB
-	MaxBackups  int         // 默认：0，不限制
-	MaxDays     int         // 默认: 7
-	TimeEncoder TimeEncoder // 默认: zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
-	Thin        bool        // 是否只打印时间和传入内容，不包括其他额外字段，默认：否
+	Level       Level           // 默认: InfoLevel
+	LogFile     string          // 默认: ${home}/logs/${app}
+	MaxSizeMB   int             // 默认: 12*1024, 2GB
+	MaxBackups  int             // 默认：0，不限制
+	MaxDays     int             // 默认: 7
+	TimeEncoder TimeEncoder     // 默认: zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
+	Thin        bool            // 是否只打印时间和传入内容，不包括其他额外字段，默认：否
+	Sinks       []Sink          // 多路输出，为空时按 LogFile 输出 json 格式日志
+	Async       *AsyncOptions   // 异步写入配置，为空时同步写入
+	Sampler     *SamplerOptions // 日志采样配置，为空时不采样
+	Redact      *RedactOptions  // 日志脱敏配置，为空时不脱敏
+
+	RotateInterval   time.Duration // 按时间切割的间隔，例如 24h、1h、10m，最小 1 分钟，默认: 24h
+	BackupTimeFormat string        // 切割文件名 ${name}-${time}${ext} 中的时间格式，默认按间隔使用 20060102、2006010215、200601021504
+	UTC              bool          // 切割时间和文件名使用 UTC 时间，默认: 本地时间
+	Compress         Compression   // 切割文件压缩格式，默认: 不压缩
+	MaxTotalSizeMB   int           // 所有切割文件的总大小上限，超出后从最早的文件开始删除，默认：0，不限制
+
+	// 以下配置在 Reload 时只有 AddGoID 生效
+	AddCaller       bool   // 输出调用位置，默认：否
+	CallerSkip      int    // 输出调用位置时额外跳过的调用栈层数，用于再次封装 Logger 的场景
+	AddGoID         bool   // 输出协程 ID，字段名 goid
+	AddHostname     bool   // 输出主机名，字段名 hostname
+	AddInnerIP      bool   // 输出内网 IP，字段名 ip
+	StacktraceLevel *Level // 输出调用栈的最低级别，例如 &logger.ErrorLevel，默认: PanicLevel
+}
+
+// Sink 日志输出目标，每个 Sink 可以单独设置级别和编码格式
+// 例如：全部日志写入 app.log，Warn 以上级别再写入 app.error.log，开发环境同时输出到控制台
+type Sink struct {
+	Level    Level          // 最低输出级别，同时受 Logger.SetLevel 限制，默认: InfoLevel
+	Encoding Encoding       // 编码格式，默认: EncodingJSON
+	LogFile  string         // 日志文件，使用 Options 中的切割配置
+	Writer   io.Writer      // 自定义输出，LogFile 为空时生效，两者都为空时输出到 stdout
+	Remote   *RemoteOptions // 输出到 syslog 或日志采集 agent，设置后 LogFile 和 Writer 不生效
+	Thin     bool           // 同 Options.Thin
 }
 
 func defaultOptions(opt *Options) {
@@ -83,6 +143,22 @@ func defaultOptions(opt *Options) {
 	if opt.MaxDays == 0 {
 		opt.MaxDays = 15
 	}
+	if opt.RotateInterval == 0 {
+		opt.RotateInterval = time.Hour * 24
+	}
+	if opt.RotateInterval < time.Minute {
+		opt.RotateInterval = time.Minute
+	}
+	if opt.BackupTimeFormat == "" {
+		switch {
+		case opt.RotateInterval >= time.Hour*24:
+			opt.BackupTimeFormat = backupDayFormat
+		case opt.RotateInterval >= time.Hour:
+			opt.BackupTimeFormat = backupHourFormat
+		default:
+			opt.BackupTimeFormat = backupMinuteFormat
+		}
+	}
 	if opt.TimeEncoder == nil {
 		opt.TimeEncoder = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
 	}
@@ -95,58 +171,126 @@ func New(opt *Options, opts ...zap.Option) Logger {
 	}
 	defaultOptions(opt)
 
-	jl := &lumberjack.Logger{
-		Filename:   opt.LogFile,
-		MaxSize:    opt.MaxSizeMB,
-		MaxBackups: opt.MaxBackups,
-		MaxAge:     opt.MaxDays,
-		LocalTime:  true,
-	}
-	rw := &rotateWriter{
-		Logger: jl,
-	}
-	fstat, err := os.Stat(opt.LogFile)
-	if err == nil && fstat.Size() > 0 {
-		// 记录文件最后修改位置
-		rw.date = fstat.ModTime().Format(backupDayFormat)
+	atomicLevel := zap.NewAtomicLevelAt(opt.Level)
+	root := newReloadableCore(newSinkState(opt))
+	lg := newWithZap(zap.New(root), atomicLevel, append(enrichOptions(opt), opts...)...)
+	lg.root = root
+	if fields := staticFields(opt); len(fields) > 0 {
+		lg.log = lg.log.With(fields...)
 	}
-	w := zapcore.AddSync(rw)
+	return lg
+}
+
+// newSinkState 根据配置创建所有输出
+func newSinkState(opt *Options) *sinkState {
+	sinks := opt.Sinks
+	if len(sinks) == 0 {
+		// 默认单个输出，只受 Logger 的日志级别控制
+		sinks = []Sink{{
+			Level:   DebugLevel,
+			LogFile: opt.LogFile,
+			Thin:    opt.Thin,
+		}}
+	}
+	state := &sinkState{}
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		cores = append(cores, newSinkCore(opt, sink, state))
+	}
+	core := zapcore.NewTee(cores...)
+	if opt.Sampler != nil {
+		core = newSamplerCore(core, opt.Sampler)
+	}
+	state.core = core
+	return state
+}
+
+// newSinkCore 根据 Sink 配置创建 zapcore.Core，打开的文件和异步写入记录到 state
+// Logger 的日志级别在 checkLevel 中判断，这里只判断 Sink 的最低级别，保证模块级别可以低于 Logger 的级别
+func newSinkCore(opt *Options, sink Sink, state *sinkState) zapcore.Core {
 	var encoderConfig zapcore.EncoderConfig
-	if opt.Thin {
+	switch {
+	case sink.Thin:
 		encoderConfig = newThinEncoderConfig()
-	} else {
+	case sink.Encoding == EncodingConsole:
+		encoderConfig = newConsoleEncoderConfig()
+	default:
 		encoderConfig = newLogEncoderConfig()
 	}
-	atomicLevel := zap.NewAtomicLevelAt(opt.Level)
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		w,
-		atomicLevel,
-	)
-	l := zap.New(core)
-	return newWithZap(l, atomicLevel, opts...)
+	encoderConfig.EncodeTime = opt.TimeEncoder
+
+	var encoder zapcore.Encoder
+	if sink.Encoding == EncodingConsole {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	var w zapcore.WriteSyncer
+	var lw levelWriter
+	switch {
+	case sink.Remote != nil:
+		rw := newRemoteWriter(sink.Remote)
+		state.remote = append(state.remote, rw)
+		state.closers = append(state.closers, rw)
+		lw = rw
+	case sink.LogFile != "":
+		rw := newRotateWriter(opt, sink.LogFile)
+		state.closers = append(state.closers, rw)
+		w = zapcore.AddSync(rw)
+	case sink.Writer != nil:
+		w = zapcore.AddSync(sink.Writer)
+	default:
+		w = zapcore.Lock(os.Stdout)
+	}
+
+	minLevel := sink.Level
+	enabler := zap.LevelEnablerFunc(func(lv Level) bool {
+		return lv >= minLevel
+	})
+	var core zapcore.Core
+	switch {
+	case opt.Async != nil:
+		if lw == nil {
+			lw = syncerLevelWriter{WriteSyncer: w}
+		}
+		aw := newAsyncWriter(lw, opt.Async)
+		state.async = append(state.async, aw)
+		state.closers = append(state.closers, aw)
+		core = newLevelCore(encoder, aw, enabler)
+	case lw != nil:
+		core = newLevelCore(encoder, lw, enabler)
+	default:
+		core = zapcore.NewCore(encoder, w, enabler)
+	}
+	if opt.Redact != nil {
+		core = newRedactCore(core, opt.Redact)
+	}
+	if opt.AddGoID {
+		core = newGoIDCore(core)
+	}
+	return core
 }
 
 func NewConsole(opts ...zap.Option) Logger {
-	encoderConfig := zap.NewDevelopmentEncoderConfig()
-	encoderConfig.TimeKey = "time"
-	encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
-	encoderConfig.FunctionKey = "fn"
-	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+	encoderConfig := newConsoleEncoderConfig()
 
 	atomicLevel := zap.NewAtomicLevelAt(zapcore.DebugLevel)
 	config := zap.NewDevelopmentConfig()
 	config.EncoderConfig = encoderConfig
 	config.OutputPaths = []string{"stdout"}
-	config.Level = atomicLevel
+	// 日志级别在 checkLevel 中判断
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
 
 	l, _ := config.Build()
 	return newWithZap(l, atomicLevel, opts...)
 }
 
-func newWithZap(l *zap.Logger, atomicLevel zap.AtomicLevel, opts ...zap.Option) Logger {
+func newWithZap(l *zap.Logger, atomicLevel zap.AtomicLevel, opts ...zap.Option) *logger {
 	options := []zap.Option{
 		zap.AddStacktrace(zap.PanicLevel),
+		// 跳过 logger 的方法，输出调用方位置
+		zap.AddCallerSkip(1),
 	}
 	options = append(options, opts...)
 	l = l.WithOptions(options...)
@@ -165,6 +309,15 @@ func newLogEncoderConfig() zapcore.EncoderConfig {
 	return encoderConfig
 }
 
+func newConsoleEncoderConfig() zapcore.EncoderConfig {
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
+	encoderConfig.FunctionKey = "fn"
+	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+	return encoderConfig
+}
+
 // newThinEncoderConfig 只打印时间和传入数据
 func newThinEncoderConfig() zapcore.EncoderConfig {
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -181,10 +334,7 @@ func newThinEncoderConfig() zapcore.EncoderConfig {
 
 func (l *logger) With(fields ...zap.Field) Logger {
 	if len(fields) > 0 {
-		return &logger{
-			atomicLevel: l.atomicLevel,
-			log:         l.log.With(fields...),
-		}
+		return l.clone(l.log.With(fields...))
 	}
 	return l
 }
@@ -287,10 +437,66 @@ func (l *logger) Fatalf(format string, args ...interface{}) {
 	l.log.Fatal(getMessage(format, args))
 }
 
+// clone 复制 logger 的共享状态，替换底层 zap.Logger
+func (l *logger) clone(log *zap.Logger) *logger {
+	c := *l
+	c.log = log
+	return &c
+}
+
+func (l *logger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	c := l.clone(l.log.Named(name))
+	c.module = childModule(l.module, name)
+	return c
+}
+
+func (l *logger) WithContext(ctx context.Context) Logger {
+	return l.With(ContextFields(ctx)...)
+}
+
+func (l *logger) DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if !l.checkLevel(DebugLevel) {
+		return
+	}
+	l.log.Debug(msg, append(fields, ContextFields(ctx)...)...)
+}
+
+func (l *logger) InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if !l.checkLevel(InfoLevel) {
+		return
+	}
+	l.log.Info(msg, append(fields, ContextFields(ctx)...)...)
+}
+
+func (l *logger) WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if !l.checkLevel(WarnLevel) {
+		return
+	}
+	l.log.Warn(msg, append(fields, ContextFields(ctx)...)...)
+}
+
+func (l *logger) ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if !l.checkLevel(ErrorLevel) {
+		return
+	}
+	l.log.Error(msg, append(fields, ContextFields(ctx)...)...)
+}
+
 func (l *logger) SetLevel(level Level) {
+	if l.module != nil {
+		SetModuleLevel(l.module.module, level)
+		return
+	}
 	l.atomicLevel.SetLevel(level)
 }
 
+func (l *logger) GetLevel() Level {
+	return l.effectiveLevel()
+}
+
 func (l *logger) Flush() {
 	err := l.log.Sync()
 	if err != nil {
@@ -298,8 +504,44 @@ func (l *logger) Flush() {
 	}
 }
 
+// AsyncDropped 返回异步写入模式下因队列满被丢弃的日志条数
+func AsyncDropped(l Logger) uint64 {
+	lg, ok := l.(*logger)
+	if !ok || lg.root == nil {
+		return 0
+	}
+	var dropped uint64
+	for _, aw := range lg.root.state.Load().async {
+		dropped += aw.Dropped()
+	}
+	return dropped
+}
+
+// RemoteDropped 返回远程输出因断开连接缓存已满被丢弃的日志条数
+func RemoteDropped(l Logger) uint64 {
+	lg, ok := l.(*logger)
+	if !ok || lg.root == nil {
+		return 0
+	}
+	var dropped uint64
+	for _, rw := range lg.root.state.Load().remote {
+		dropped += rw.Dropped()
+	}
+	return dropped
+}
+
 func (l *logger) checkLevel(lv Level) bool {
-	return l.atomicLevel.Level() <= lv
+	return l.effectiveLevel() <= lv
+}
+
+// effectiveLevel 命名 Logger 优先使用模块日志级别
+func (l *logger) effectiveLevel() Level {
+	if l.module != nil {
+		if level, ok := l.module.resolve(); ok {
+			return level
+		}
+	}
+	return l.atomicLevel.Level()
 }
 
 type Level = zapcore.Level
@@ -362,17 +604,3 @@ func getMessage(template string, fmtArgs []interface{}) string {
 	}
 	return fmt.Sprint(fmtArgs...)
 }
-
-func backupDayName(name string, local bool) string {
-	dir := filepath.Dir(name)
-	filename := filepath.Base(name)
-	ext := filepath.Ext(filename)
-	prefix := filename[:len(filename)-len(ext)]
-	t := currentTime()
-	if !local {
-		t = t.UTC()
-	}
-
-	date := t.Format(backupDayFormat)
-	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, date, ext))
-}
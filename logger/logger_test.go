@@ -1,11 +1,16 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 
 	"github.com/fengjx/go-halo/halo"
@@ -102,3 +107,100 @@ func TestThin(t *testing.T) {
 	})
 	log.Info("", zap.String("foo", "bar"))
 }
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.log")
+	errLog := filepath.Join(dir, "app.error.log")
+	console := &bytes.Buffer{}
+	log := New(&Options{
+		Level: DebugLevel,
+		Sinks: []Sink{
+			{Level: DebugLevel, LogFile: appLog},
+			{Level: WarnLevel, LogFile: errLog},
+			{Level: DebugLevel, Encoding: EncodingConsole, Writer: console},
+		},
+	})
+	log.Debug("debug msg")
+	log.Info("info msg")
+	log.Warn("warn msg")
+	log.Error("error msg")
+	log.Flush()
+
+	appData, err := os.ReadFile(appLog)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(appData), "\n"))
+
+	errData, err := os.ReadFile(errLog)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(errData), "info msg")
+	assert.Contains(t, string(errData), "warn msg")
+	assert.Contains(t, string(errData), "error msg")
+
+	assert.Contains(t, console.String(), "DEBUG")
+
+	// SetLevel 对所有 sink 生效
+	log.SetLevel(ErrorLevel)
+	console.Reset()
+	log.Warn("warn msg2")
+	log.Error("error msg2")
+	assert.NotContains(t, console.String(), "warn msg2")
+	assert.Contains(t, console.String(), "error msg2")
+}
+
+func TestRotateHourly(t *testing.T) {
+	// 固定在整点，避免在 59 分运行时前进 1 分钟跨过整点
+	fakeCurrentTime = time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	currentTime = fakeTime
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	dir := t.TempDir()
+	logFilepath := filepath.Join(dir, "hourly.log")
+	log := New(&Options{
+		LogFile:        logFilepath,
+		RotateInterval: time.Hour,
+		UTC:            true,
+	})
+	log.Info("hour 1")
+	backup := filepath.Join(dir, "hourly-"+fakeTime().UTC().Format(backupHourFormat)+".log")
+
+	makeFakeTime(time.Minute)
+	log.Info("same hour")
+	_, err := os.Stat(backup)
+	assert.True(t, os.IsNotExist(err))
+
+	makeFakeTime(time.Hour)
+	log.Info("hour 2")
+	data, err := os.ReadFile(backup)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "hour 1")
+	assert.Contains(t, string(data), "same hour")
+	data, err = os.ReadFile(logFilepath)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+}
+
+func TestRotateMinutes(t *testing.T) {
+	currentTime = fakeTime
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	dir := t.TempDir()
+	logFilepath := filepath.Join(dir, "minutes.log")
+	log := New(&Options{
+		LogFile:          logFilepath,
+		RotateInterval:   time.Minute * 10,
+		BackupTimeFormat: "2006-01-02_1504",
+	})
+	w := &rotateWriter{interval: time.Minute * 10}
+	period := w.periodOf(fakeTime())
+	log.Info("period 1")
+	makeFakeTime(time.Minute * 10)
+	log.Info("period 2")
+	_, err := os.Stat(filepath.Join(dir, "minutes-"+period.Format("2006-01-02_1504")+".log"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, period.Minute()%10)
+}
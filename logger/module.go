@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// moduleRules 模块日志级别规则表，所有 Named 创建的 Logger 共享
+type moduleRules struct {
+	mu      sync.RWMutex
+	rules   map[string]Level
+	version atomic.Uint64
+}
+
+var modules = &moduleRules{
+	rules: make(map[string]Level),
+}
+
+// SetModuleLevel 设置模块日志级别，对 Named 创建的 Logger 生效，优先级高于根 Logger 的 SetLevel
+// pattern 为模块名，Named 多级嵌套时模块名使用 . 连接，/ 与 . 等价
+// 支持 * 结尾的前缀匹配，例如 order.payment、order.*、*，多条规则匹配时精确匹配优先，其次最长前缀优先
+func SetModuleLevel(pattern string, level Level) {
+	modules.mu.Lock()
+	defer modules.mu.Unlock()
+	modules.rules[normalizeModule(pattern)] = level
+	modules.version.Add(1)
+}
+
+// DeleteModuleLevel 删除模块日志级别规则
+func DeleteModuleLevel(pattern string) {
+	modules.mu.Lock()
+	defer modules.mu.Unlock()
+	delete(modules.rules, normalizeModule(pattern))
+	modules.version.Add(1)
+}
+
+// SetModuleLevels 整体替换模块日志级别规则表
+func SetModuleLevels(rules map[string]Level) {
+	modules.mu.Lock()
+	defer modules.mu.Unlock()
+	modules.rules = make(map[string]Level, len(rules))
+	for pattern, level := range rules {
+		modules.rules[normalizeModule(pattern)] = level
+	}
+	modules.version.Add(1)
+}
+
+// ModuleLevels 返回模块日志级别规则表
+func ModuleLevels() map[string]Level {
+	modules.mu.RLock()
+	defer modules.mu.RUnlock()
+	rules := make(map[string]Level, len(modules.rules))
+	for pattern, level := range modules.rules {
+		rules[pattern] = level
+	}
+	return rules
+}
+
+// exact 是否有与模块名完全相同的规则
+func (r *moduleRules) exact(module string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.rules[module]
+	return ok
+}
+
+// match 返回模块匹配的日志级别
+func (r *moduleRules) match(module string) (Level, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if level, ok := r.rules[module]; ok {
+		return level, true
+	}
+	var (
+		level     Level
+		matched   bool
+		prefixLen = -1
+	)
+	for pattern, lv := range r.rules {
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := pattern[:len(pattern)-1]
+		if len(prefix) > prefixLen && strings.HasPrefix(module, prefix) {
+			level, matched, prefixLen = lv, true, len(prefix)
+		}
+	}
+	return level, matched
+}
+
+// moduleLevel 命名 Logger 的日志级别，规则表没有变化时使用缓存
+type moduleLevel struct {
+	module string
+	cache  atomic.Pointer[moduleLevelCache]
+}
+
+type moduleLevelCache struct {
+	version uint64
+	level   Level
+	ok      bool
+}
+
+func newModuleLevel(module string) *moduleLevel {
+	return &moduleLevel{module: normalizeModule(module)}
+}
+
+func (m *moduleLevel) resolve() (Level, bool) {
+	version := modules.version.Load()
+	if c := m.cache.Load(); c != nil && c.version == version {
+		return c.level, c.ok
+	}
+	level, ok := modules.match(m.module)
+	m.cache.Store(&moduleLevelCache{version: version, level: level, ok: ok})
+	return level, ok
+}
+
+// childModule 返回子模块名，与 zap.Logger.Named 一致使用 . 连接
+func childModule(parent *moduleLevel, name string) *moduleLevel {
+	if parent == nil {
+		return newModuleLevel(name)
+	}
+	return newModuleLevel(parent.module + "." + name)
+}
+
+// normalizeModule 统一模块名分隔符，order/payment 等价于 order.payment
+func normalizeModule(module string) string {
+	return strings.ReplaceAll(module, "/", ".")
+}
+
+// moduleOf 返回 Named 创建的 Logger 的模块名，不是命名 Logger 时返回空
+func moduleOf(l Logger) string {
+	var m *moduleLevel
+	switch v := l.(type) {
+	case *logger:
+		m = v.module
+	case *slogLogger:
+		m = v.module
+	}
+	if m == nil {
+		return ""
+	}
+	return m.module
+}
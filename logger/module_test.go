@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleLevel(t *testing.T) {
+	defer SetModuleLevels(nil)
+
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{{Level: DebugLevel, Writer: buf}},
+	})
+	payment := log.Named("order/payment")
+	refund := log.Named("order").Named("refund")
+	user := log.Named("user")
+
+	SetModuleLevel("order/payment", DebugLevel)
+	SetModuleLevel("order*", WarnLevel)
+	SetModuleLevel("*", ErrorLevel)
+
+	payment.Debug("payment debug")
+	refund.Info("refund info")
+	refund.Warn("refund warn")
+	user.Warn("user warn")
+	log.Info("root info")
+
+	out := buf.String()
+	assert.Contains(t, out, `"logger":"order/payment","msg":"payment debug"`)
+	assert.NotContains(t, out, "refund info")
+	assert.Contains(t, out, `"logger":"order.refund","msg":"refund warn"`)
+	assert.NotContains(t, out, "user warn")
+	assert.Contains(t, out, "root info")
+
+	assert.Equal(t, DebugLevel, payment.GetLevel())
+	assert.Equal(t, InfoLevel, log.GetLevel())
+
+	// 运行时修改规则
+	DeleteModuleLevel("order/payment")
+	assert.Equal(t, WarnLevel, payment.GetLevel())
+	DeleteModuleLevel("order*")
+	DeleteModuleLevel("*")
+	assert.Equal(t, InfoLevel, payment.With().GetLevel())
+	assert.Empty(t, ModuleLevels())
+}
+
+func TestModuleLevelSlog(t *testing.T) {
+	defer SetModuleLevels(nil)
+
+	buf := &bytes.Buffer{}
+	log := NewWithSlog(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	log.SetLevel(InfoLevel)
+	SetModuleLevel("payment", DebugLevel)
+	log.Named("payment").Debug("payment debug")
+	log.Debug("root debug")
+	assert.Contains(t, buf.String(), `"msg":"payment debug","logger":"payment"`)
+	assert.NotContains(t, buf.String(), "root debug")
+}
+
+func TestNamedSetLevel(t *testing.T) {
+	defer SetModuleLevels(nil)
+
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{{Level: DebugLevel, Writer: buf}},
+	})
+	order := log.Named("order")
+	payment := order.Named("payment")
+	refund := order.Named("refund")
+
+	// 子 Logger 修改级别不影响父 Logger 和同级模块
+	payment.SetLevel(DebugLevel)
+	assert.Equal(t, DebugLevel, payment.GetLevel())
+	assert.Equal(t, InfoLevel, log.GetLevel())
+	assert.Equal(t, InfoLevel, order.GetLevel())
+	assert.Equal(t, InfoLevel, refund.GetLevel())
+	assert.Equal(t, map[string]Level{"order.payment": DebugLevel}, ModuleLevels())
+
+	payment.Debug("payment debug")
+	refund.Debug("refund debug")
+	assert.Contains(t, buf.String(), "payment debug")
+	assert.NotContains(t, buf.String(), "refund debug")
+
+	// 根 Logger 的级别对没有模块规则的 Logger 生效
+	log.SetLevel(WarnLevel)
+	assert.Equal(t, WarnLevel, refund.GetLevel())
+	assert.Equal(t, DebugLevel, payment.GetLevel())
+
+	// / 与 . 等价
+	SetModuleLevel("order/refund", ErrorLevel)
+	assert.Equal(t, ErrorLevel, refund.GetLevel())
+	SetModuleLevel("order/*", DebugLevel)
+	assert.Equal(t, DebugLevel, order.Named("ship").GetLevel())
+}
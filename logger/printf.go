@@ -0,0 +1,29 @@
+package logger
+
+import "strings"
+
+// PrintfLogger 把 Logger 适配为 Printf 风格的日志接口，例如 worker.Logger 和 worker.LevelLogger。
+// Printf 使用 Info 级别输出，Warnf 和 Errorf 分别使用 Warn 和 Error 级别输出
+type PrintfLogger struct {
+	log Logger
+}
+
+// NewPrintfLogger 创建 PrintfLogger
+func NewPrintfLogger(log Logger) PrintfLogger {
+	return PrintfLogger{log: log}
+}
+
+// Printf 与 log.Printf 语义相同，去掉结尾的换行
+func (l PrintfLogger) Printf(format string, args ...interface{}) {
+	l.log.Infof(strings.TrimSuffix(format, "\n"), args...)
+}
+
+// Warnf 使用 Warn 级别输出
+func (l PrintfLogger) Warnf(format string, args ...interface{}) {
+	l.log.Warnf(strings.TrimSuffix(format, "\n"), args...)
+}
+
+// Errorf 使用 Error 级别输出
+func (l PrintfLogger) Errorf(format string, args ...interface{}) {
+	l.log.Errorf(strings.TrimSuffix(format, "\n"), args...)
+}
@@ -0,0 +1,18 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestPrintfLogger(t *testing.T) {
+	log := NewTest(t)
+	p := NewPrintfLogger(log)
+	p.Printf("release")
+	p.Warnf("submit worker task timeout")
+	p.Errorf("recover panic[%v] and exit\n", "boom")
+
+	log.AssertLogged(InfoLevel, "release")
+	log.AssertLogged(WarnLevel, "submit worker task timeout")
+	log.AssertLogged(ErrorLevel, "recover panic[boom] and exit")
+	log.AssertNotLogged(ErrorLevel, "exit\n")
+}
@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/fengjx/go-halo/json"
+)
+
+const (
+	defaultReaderTimeLayout = "2006-01-02 15:04:05.000"
+	defaultFollowInterval   = time.Millisecond * 200
+	maxLogLineSize          = 1024 * 1024 * 8
+)
+
+// LogRecord 从日志文件中读取的一条日志
+type LogRecord struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  map[string]any // 日志的所有字段，包括 time、level、msg
+	Raw     string         // 原始内容
+	File    string         // 所在文件
+}
+
+// Query 日志查询条件，零值表示不限制
+type Query struct {
+	Start   time.Time         // 开始时间（包含）
+	End     time.Time         // 结束时间（不包含）
+	Levels  []Level           // 日志级别
+	Message string            // 日志内容包含的字符串
+	Fields  map[string]string // 字段值相等，按字符串比较
+	Limit   int               // 最多返回条数
+}
+
+// Reader 读取 New 输出的 json 格式日志文件，包括切割文件和压缩文件
+type Reader struct {
+	filename   string
+	timeLayout string
+	location   *time.Location
+	interval   time.Duration
+}
+
+// NewReader 创建日志读取器，filename 为 Options.LogFile
+func NewReader(filename string) *Reader {
+	return &Reader{
+		filename:   filename,
+		timeLayout: defaultReaderTimeLayout,
+		location:   time.Local,
+		interval:   defaultFollowInterval,
+	}
+}
+
+// SetTimeLayout 设置日志中时间字段的格式，需要与 Options.TimeEncoder 一致，默认: 2006-01-02 15:04:05.000
+func (r *Reader) SetTimeLayout(layout string, loc *time.Location) *Reader {
+	r.timeLayout = layout
+	if loc != nil {
+		r.location = loc
+	}
+	return r
+}
+
+// Files 返回需要读取的文件，按时间从早到晚排序，最后是当前日志文件
+func (r *Reader) Files() []string {
+	dir := filepath.Dir(r.filename)
+	backups := listBackups(r.filename)
+	files := make([]string, 0, len(backups)+1)
+	for i := len(backups) - 1; i >= 0; i-- {
+		files = append(files, filepath.Join(dir, backups[i].Name()))
+	}
+	if _, err := os.Stat(r.filename); err == nil {
+		files = append(files, r.filename)
+	}
+	return files
+}
+
+// Scan 按时间顺序读取所有日志文件，fn 返回 false 时停止
+func (r *Reader) Scan(q *Query, fn func(rec *LogRecord) bool) error {
+	if q == nil {
+		q = &Query{}
+	}
+	count := 0
+	for _, file := range r.Files() {
+		if !q.Start.IsZero() {
+			// 文件最后修改时间早于开始时间，不会有匹配的日志
+			if info, err := os.Stat(file); err == nil && info.ModTime().Before(q.Start) {
+				continue
+			}
+		}
+		stop, err := r.scanFile(file, q, func(rec *LogRecord) bool {
+			count++
+			if !fn(rec) {
+				return false
+			}
+			return q.Limit <= 0 || count < q.Limit
+		})
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *Reader) scanFile(file string, q *Query, fn func(rec *LogRecord) bool) (stop bool, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var in io.Reader = f
+	switch {
+	case strings.HasSuffix(file, gzipExt):
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", file, err)
+		}
+		defer gr.Close()
+		in = gr
+	case strings.HasSuffix(file, zstdExt):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", file, err)
+		}
+		defer zr.Close()
+		in = zr
+	}
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for sc.Scan() {
+		rec := r.parse(file, sc.Bytes())
+		if rec == nil || !q.Match(rec) {
+			continue
+		}
+		if !fn(rec) {
+			return true, nil
+		}
+	}
+	return false, sc.Err()
+}
+
+// Follow 从当前日志文件末尾开始持续读取新的日志，文件被切割后自动切换到新文件，ctx 取消或 fn 返回 false 时停止
+func (r *Reader) Follow(ctx context.Context, q *Query, fn func(rec *LogRecord) bool) error {
+	if q == nil {
+		q = &Query{}
+	}
+	var (
+		f       *os.File
+		offset  int64
+		pending []byte
+	)
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+		}
+	}()
+	open := func(seekEnd bool) error {
+		nf, err := os.Open(r.filename)
+		if err != nil {
+			return err
+		}
+		offset = 0
+		if seekEnd {
+			if offset, err = nf.Seek(0, io.SeekEnd); err != nil {
+				_ = nf.Close()
+				return err
+			}
+		}
+		if f != nil {
+			_ = f.Close()
+		}
+		f = nf
+		pending = nil
+		return nil
+	}
+	if err := open(true); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	count := 0
+	// emit 处理一行日志，返回 false 表示停止读取
+	emit := func(line []byte) bool {
+		rec := r.parse(r.filename, line)
+		if rec == nil || !q.Match(rec) {
+			return true
+		}
+		count++
+		return fn(rec) && (q.Limit <= 0 || count < q.Limit)
+	}
+	buf := make([]byte, 64*1024)
+	// drain 读取到文件末尾，返回 false 表示停止读取
+	drain := func() bool {
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				offset += int64(n)
+				pending = append(pending, buf[:n]...)
+				for {
+					idx := bytes.IndexByte(pending, '\n')
+					if idx < 0 {
+						break
+					}
+					line := pending[:idx]
+					pending = pending[idx+1:]
+					if !emit(line) {
+						return false
+					}
+				}
+			}
+			if err != nil {
+				return true
+			}
+		}
+	}
+	tk := time.NewTicker(r.interval)
+	defer tk.Stop()
+	for {
+		if f != nil && !drain() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tk.C:
+		}
+
+		// 检查文件是否被切割或截断
+		info, err := os.Stat(r.filename)
+		if err != nil {
+			continue
+		}
+		if f == nil {
+			_ = open(false)
+			continue
+		}
+		if fi, err := f.Stat(); err == nil && !os.SameFile(fi, info) {
+			// 上次读到末尾之后、切割之前写入旧文件的日志还没有读取，读完旧文件再切换到新文件
+			if !drain() {
+				return nil
+			}
+			if len(pending) > 0 && !emit(pending) {
+				return nil
+			}
+			_ = open(false)
+		} else if info.Size() < offset {
+			_ = open(false)
+		}
+	}
+}
+
+func (r *Reader) parse(file string, line []byte) *LogRecord {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil
+	}
+	fields := make(map[string]any)
+	if err := json.FromBytes(line, &fields); err != nil {
+		return nil
+	}
+	rec := &LogRecord{
+		Fields: fields,
+		Raw:    string(line),
+		File:   file,
+	}
+	if v, ok := fields["time"].(string); ok {
+		if t, err := time.ParseInLocation(r.timeLayout, v, r.location); err == nil {
+			rec.Time = t
+		} else if t, err = time.Parse(time.RFC3339Nano, v); err == nil {
+			rec.Time = t
+		}
+	}
+	if v, ok := fields["level"].(string); ok {
+		_ = rec.Level.UnmarshalText([]byte(v))
+	}
+	if v, ok := fields["msg"].(string); ok {
+		rec.Message = v
+	}
+	return rec
+}
+
+// Match 判断日志是否满足查询条件
+func (q *Query) Match(rec *LogRecord) bool {
+	if !q.Start.IsZero() && rec.Time.Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && !rec.Time.Before(q.End) {
+		return false
+	}
+	if len(q.Levels) > 0 {
+		found := false
+		for _, lv := range q.Levels {
+			if lv == rec.Level {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Message != "" && !strings.Contains(rec.Message, q.Message) {
+		return false
+	}
+	for k, v := range q.Fields {
+		val, ok := rec.Fields[k]
+		if !ok || fmt.Sprint(val) != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeLogLines(t *testing.T, file string, start time.Time, level string, n int) {
+	f, err := os.Create(file)
+	assert.NoError(t, err)
+	defer f.Close()
+	var w io.Writer = f
+	if filepath.Ext(file) == gzipExt {
+		gw := gzip.NewWriter(f)
+		defer gw.Close()
+		w = gw
+	}
+	for i := 0; i < n; i++ {
+		ts := start.Add(time.Minute * time.Duration(i)).Format(defaultReaderTimeLayout)
+		line := fmt.Sprintf(`{"level":"%s","time":"%s","msg":"request %d","uid":%d,"path":"/api"}`+"\n", level, ts, i, i)
+		_, err = io.WriteString(w, line)
+		assert.NoError(t, err)
+	}
+}
+
+func TestReaderScan(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	day1 := time.Date(2023, 1, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day2.AddDate(0, 0, 1)
+	writeLogLines(t, filepath.Join(dir, "app-20230101.log.gz"), day1, "info", 5)
+	writeLogLines(t, filepath.Join(dir, "app-20230102.log"), day2, "error", 5)
+	writeLogLines(t, name, day3, "warn", 5)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app-error.log"), []byte("{}\n"), 0644))
+	for i, f := range []string{"app-20230101.log.gz", "app-20230102.log", "app.log"} {
+		mtime := day1.AddDate(0, 0, i).Add(time.Hour)
+		assert.NoError(t, os.Chtimes(filepath.Join(dir, f), mtime, mtime))
+	}
+
+	r := NewReader(name)
+	assert.Len(t, r.Files(), 3)
+
+	var all []*LogRecord
+	assert.NoError(t, r.Scan(nil, func(rec *LogRecord) bool {
+		all = append(all, rec)
+		return true
+	}))
+	assert.Len(t, all, 15)
+	assert.Equal(t, day1, all[0].Time)
+	assert.Equal(t, InfoLevel, all[0].Level)
+	assert.Equal(t, "request 0", all[0].Message)
+	assert.Equal(t, WarnLevel, all[14].Level)
+
+	var matched []*LogRecord
+	q := &Query{
+		Start:   day2.Add(time.Minute),
+		End:     day3.Add(time.Minute * 3),
+		Levels:  []Level{ErrorLevel, WarnLevel},
+		Message: "request",
+		Fields:  map[string]string{"path": "/api"},
+	}
+	assert.NoError(t, r.Scan(q, func(rec *LogRecord) bool {
+		matched = append(matched, rec)
+		return true
+	}))
+	assert.Len(t, matched, 7)
+
+	matched = nil
+	q = &Query{Fields: map[string]string{"uid": "3"}, Limit: 2}
+	assert.NoError(t, r.Scan(q, func(rec *LogRecord) bool {
+		matched = append(matched, rec)
+		return true
+	}))
+	assert.Len(t, matched, 2)
+	assert.Equal(t, day1.Add(time.Minute*3), matched[0].Time)
+	assert.Equal(t, day2.Add(time.Minute*3), matched[1].Time)
+}
+
+func TestReaderFollow(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	writeLogLines(t, name, time.Now(), "info", 3)
+
+	r := NewReader(name)
+	r.interval = time.Millisecond * 10
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		msgs []string
+	)
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Follow(ctx, &Query{Levels: []Level{ErrorLevel}, Limit: 2}, func(rec *LogRecord) bool {
+			mu.Lock()
+			msgs = append(msgs, rec.Message)
+			mu.Unlock()
+			return true
+		})
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, _ = f.WriteString(`{"level":"info","msg":"skip"}` + "\n")
+	_, _ = f.WriteString(`{"level":"error","msg":"first"}` + "\n")
+	_ = f.Close()
+	time.Sleep(time.Millisecond * 50)
+
+	// 模拟切割：重命名后创建新文件
+	assert.NoError(t, os.Rename(name, filepath.Join(dir, "app-20230101.log")))
+	assert.NoError(t, os.WriteFile(name, []byte(`{"level":"error","msg":"second"}`+"\n"), 0644))
+
+	assert.NoError(t, <-done)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"first", "second"}, msgs)
+}
+
+func TestReaderFollowRotate(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(name, nil, 0644))
+
+	r := NewReader(name)
+	r.interval = time.Millisecond * 100
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		msgs []string
+	)
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Follow(ctx, &Query{Limit: 6}, func(rec *LogRecord) bool {
+			mu.Lock()
+			msgs = append(msgs, rec.Message)
+			mu.Unlock()
+			return true
+		})
+	}()
+	time.Sleep(time.Millisecond * 150)
+
+	// 写入后立即切割，旧文件中还没有读取的日志不会丢失
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		_, _ = fmt.Fprintf(f, `{"level":"info","msg":"old-%d"}`+"\n", i)
+	}
+	_ = f.Close()
+	assert.NoError(t, os.Rename(name, filepath.Join(dir, "app-20230101.log")))
+	f, err = os.Create(name)
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		_, _ = fmt.Fprintf(f, `{"level":"info","msg":"new-%d"}`+"\n", i)
+	}
+	_ = f.Close()
+
+	assert.NoError(t, <-done)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"old-1", "old-2", "old-3", "new-1", "new-2", "new-3"}, msgs)
+}
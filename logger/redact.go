@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultRedactMask = "******"
+
+// 手机号和身份证号要求前后不是数字，避免把时间戳、订单号等更长数字中的一段当成手机号脱敏。
+// 前后边界需要匹配一个字符，所以用分组标记需要脱敏的部分
+var (
+	// RedactPhone 手机号
+	RedactPhone = regexp.MustCompile(`(?:^|\D)(1[3-9]\d{9})(?:\D|$)`)
+	// RedactEmail 邮箱
+	RedactEmail = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+	// RedactIDCard 身份证号
+	RedactIDCard = regexp.MustCompile(`(?:^|\D)(\d{17}[\dXx])(?:\D|$)`)
+)
+
+// RedactOptions 日志脱敏配置
+// 只处理日志内容和字符串、[]byte、Stringer、error 类型的顶层字段，
+// zap.Any、zap.Object、zap.Array、zap.Reflect 等字段内部的值不会脱敏，敏感数据需要先用 Masked 或 String 字段输出
+type RedactOptions struct {
+	Keys     []string         // 按字段名脱敏，忽略大小写，例如 password、token
+	Patterns []*regexp.Regexp // 对日志内容和字符串字段中匹配的部分脱敏，例如 RedactPhone、RedactEmail，包含分组时只替换第一个分组
+	Mask     string           // Keys 匹配的字段替换后的内容，默认: ******
+}
+
+// WithRedact 返回开启脱敏的 zap.Option，可用于 NewConsole
+func WithRedact(opt *RedactOptions) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newRedactCore(core, opt)
+	})
+}
+
+// Masked 返回部分隐藏的字段，例如 Masked("phone", "13812345678") 输出 138****5678
+func Masked(key string, val string) zap.Field {
+	return zap.String(key, MaskString(val))
+}
+
+// MaskString 隐藏字符串中间部分
+// 长度 >= 8 保留前 3 位和后 4 位，长度 >= 3 保留首尾各 1 位，否则全部隐藏
+func MaskString(s string) string {
+	r := []rune(s)
+	n := len(r)
+	var head, tail int
+	switch {
+	case n >= 8:
+		head, tail = 3, 4
+	case n >= 3:
+		head, tail = 1, 1
+	}
+	return string(r[:head]) + strings.Repeat("*", n-head-tail) + string(r[n-tail:])
+}
+
+type redactor struct {
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// redactCore 在写入前对日志内容和字段脱敏，与编码格式无关
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func newRedactCore(core zapcore.Core, opt *RedactOptions) zapcore.Core {
+	r := &redactor{
+		keys:     make(map[string]struct{}, len(opt.Keys)),
+		patterns: opt.Patterns,
+		mask:     opt.Mask,
+	}
+	if r.mask == "" {
+		r.mask = defaultRedactMask
+	}
+	for _, key := range opt.Keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+	return &redactCore{Core: core, r: r}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{
+		Core: c.Core.With(c.r.fields(fields)),
+		r:    c.r,
+	}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.text(ent.Message)
+	return c.Core.Write(ent, c.r.fields(fields))
+}
+
+func (r *redactor) text(s string) string {
+	for _, p := range r.patterns {
+		s = maskPattern(p, s)
+	}
+	return s
+}
+
+// maskPattern 隐藏 s 中匹配的部分，p 包含分组时只隐藏第一个分组，
+// 下一次从分组结束的位置继续匹配，相邻的两个值可以共用中间的边界字符
+func maskPattern(p *regexp.Regexp, s string) string {
+	if p.NumSubexp() == 0 {
+		return p.ReplaceAllStringFunc(s, MaskString)
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for pos := 0; pos < len(s); {
+		loc := p.FindStringSubmatchIndex(s[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[2], pos+loc[3]
+		if loc[2] < 0 || end == start {
+			pos += max(loc[1], 1)
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(MaskString(s[start:end]))
+		last, pos = end, end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// fields 返回脱敏后的字段，不修改调用方传入的切片
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		rf, changed := r.field(f)
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, rf)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func (r *redactor) field(f zapcore.Field) (zapcore.Field, bool) {
+	if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+		return f, false
+	}
+	if _, ok := r.keys[strings.ToLower(f.Key)]; ok {
+		return zap.String(f.Key, r.mask), true
+	}
+	if len(r.patterns) == 0 {
+		return f, false
+	}
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ByteStringType:
+		s = string(f.Interface.([]byte))
+	case zapcore.StringerType:
+		s = fmt.Sprint(f.Interface)
+	case zapcore.ErrorType:
+		s = f.Interface.(error).Error()
+	default:
+		return f, false
+	}
+	rs := r.text(s)
+	if rs == s {
+		return f, false
+	}
+	return zap.String(f.Key, rs), true
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRedact(t *testing.T) {
+	buf := &bytes.Buffer{}
+	thinBuf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{
+			{Writer: buf},
+			{Writer: thinBuf, Thin: true},
+		},
+		Redact: &RedactOptions{
+			Keys:     []string{"password", "Token"},
+			Patterns: []*regexp.Regexp{RedactPhone, RedactEmail},
+		},
+	})
+	log.With(zap.String("token", "abc")).Info("user 13812345678 login",
+		zap.String("PASSWORD", "123456"),
+		zap.String("email", "contact foo.bar@example.com"),
+		zap.Error(errors.New("phone 13987654321 not found")),
+		zap.Int("uid", 1000),
+	)
+	out := buf.String()
+	assert.Contains(t, out, `"msg":"user 138****5678 login"`)
+	assert.Contains(t, out, `"token":"******"`)
+	assert.Contains(t, out, `"PASSWORD":"******"`)
+	assert.NotContains(t, out, "foo.bar@example.com")
+	assert.Contains(t, out, `"error":"phone 139****4321 not found"`)
+	assert.Contains(t, out, `"uid":1000`)
+
+	thin := thinBuf.String()
+	assert.Contains(t, thin, `"token":"******"`)
+	assert.Contains(t, thin, `"PASSWORD":"******"`)
+	assert.NotContains(t, thin, "foo.bar@example.com")
+}
+
+func TestMasked(t *testing.T) {
+	assert.Equal(t, "138****5678", MaskString("13812345678"))
+	assert.Equal(t, "a*c", MaskString("abc"))
+	assert.Equal(t, "**", MaskString("ab"))
+	assert.Equal(t, "张*三", MaskString("张小三"))
+
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: buf}},
+	})
+	log.Info("masked", Masked("phone", "13812345678"))
+	assert.Contains(t, buf.String(), `"phone":"138****5678"`)
+}
+
+func TestRedactPatternBoundary(t *testing.T) {
+	r := &redactor{patterns: []*regexp.Regexp{RedactPhone, RedactIDCard}}
+	// 前后都是数字边界时才脱敏
+	assert.Equal(t, "138****5678", r.text("13812345678"))
+	assert.Equal(t, "call 138****5678,139****4321.", r.text("call 13812345678,13987654321."))
+	assert.Equal(t, "id=110***********123X", r.text("id=11010119900101123X"))
+	assert.Equal(t, "x138****5678", r.text("x13812345678"))
+	// 毫秒时间戳、订单号、trace id 等更长的数字不脱敏
+	for _, s := range []string{
+		"ts=1700000000000",
+		"order 138123456789012",
+		"trace 91381234567800",
+		"id 1101011990010112345",
+	} {
+		assert.Equal(t, s, r.text(s))
+	}
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fengjx/go-halo/json"
+)
+
+type namedLogger struct {
+	log      Logger
+	origin   Level       // 临时修改前的级别，TTL 到期后恢复
+	noRule   bool        // 命名 Logger 临时修改前没有自身模块的规则，TTL 到期后删除规则而不是恢复级别
+	timer    *time.Timer // TTL 定时器，为空表示没有待恢复的修改
+	expireAt time.Time
+}
+
+var (
+	registry     = make(map[string]*namedLogger)
+	registryLock sync.Mutex
+)
+
+// Register 注册命名 Logger，注册后可以通过 LevelHandler 在运行时修改日志级别
+func Register(name string, l Logger) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if nl, ok := registry[name]; ok && nl.timer != nil {
+		nl.timer.Stop()
+	}
+	registry[name] = &namedLogger{log: l}
+}
+
+// Unregister 取消注册
+func Unregister(name string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if nl, ok := registry[name]; ok && nl.timer != nil {
+		nl.timer.Stop()
+	}
+	delete(registry, name)
+}
+
+// Lookup 根据名称查找已注册的 Logger
+func Lookup(name string) (Logger, bool) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	nl, ok := registry[name]
+	if !ok {
+		return nil, false
+	}
+	return nl.log, true
+}
+
+// SetNamedLevel 修改已注册 Logger 的日志级别，name 为空时修改所有 Logger
+// ttl > 0 时，到期后自动恢复为修改前的级别
+func SetNamedLevel(name string, level Level, ttl time.Duration) error {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if name == "" {
+		for n := range registry {
+			setNamedLevelLocked(n, level, ttl)
+		}
+		return nil
+	}
+	if _, ok := registry[name]; !ok {
+		return fmt.Errorf("logger %s not registered", name)
+	}
+	setNamedLevelLocked(name, level, ttl)
+	return nil
+}
+
+func setNamedLevelLocked(name string, level Level, ttl time.Duration) {
+	nl := registry[name]
+	if nl.timer != nil {
+		nl.timer.Stop()
+		nl.timer = nil
+		nl.expireAt = time.Time{}
+	} else {
+		nl.origin = nl.log.GetLevel()
+		module := moduleOf(nl.log)
+		nl.noRule = module != "" && !modules.exact(module)
+	}
+	nl.log.SetLevel(level)
+	if ttl <= 0 {
+		return
+	}
+	nl.expireAt = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		// 定时器已被新的修改替换
+		if nl.timer != timer {
+			return
+		}
+		if nl.noRule {
+			// 恢复为继承根 Logger 和前缀规则的级别
+			DeleteModuleLevel(moduleOf(nl.log))
+		} else {
+			nl.log.SetLevel(nl.origin)
+		}
+		nl.timer = nil
+		nl.expireAt = time.Time{}
+	})
+	nl.timer = timer
+}
+
+// LevelInfo 日志级别信息
+type LevelInfo struct {
+	Name     string     `json:"name"`
+	Level    string     `json:"level"`
+	ExpireAt *time.Time `json:"expire_at,omitempty"` // 临时级别的过期时间
+}
+
+// GetNamedLevels 返回已注册 Logger 的日志级别，name 为空时返回所有
+func GetNamedLevels(name string) []LevelInfo {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	var infos []LevelInfo
+	for n, nl := range registry {
+		if name != "" && n != name {
+			continue
+		}
+		info := LevelInfo{
+			Name:  n,
+			Level: nl.log.GetLevel().String(),
+		}
+		if nl.timer != nil {
+			expireAt := nl.expireAt
+			info.ExpireAt = &expireAt
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+type levelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	TTL   string `json:"ttl"` // 例如 10m，为空表示永久生效
+}
+
+// LevelHandler 返回运行时查询和修改日志级别的 http.Handler
+//
+//	GET ?name=xxx 查询日志级别，name 为空时返回所有
+//	PUT {"name":"xxx","level":"debug","ttl":"10m"} 修改日志级别，name 为空时修改所有
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			name := r.URL.Query().Get("name")
+			infos := GetNamedLevels(name)
+			if name != "" && len(infos) == 0 {
+				writeLevelError(w, http.StatusNotFound, fmt.Errorf("logger %s not registered", name))
+				return
+			}
+			writeLevelResponse(w, infos)
+		case http.MethodPut:
+			req := &levelRequest{}
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			var level Level
+			if err := level.UnmarshalText([]byte(req.Level)); err != nil || req.Level == "" {
+				writeLevelError(w, http.StatusBadRequest, fmt.Errorf("invalid level: %s", req.Level))
+				return
+			}
+			var ttl time.Duration
+			if req.TTL != "" {
+				var err error
+				ttl, err = time.ParseDuration(req.TTL)
+				if err != nil {
+					writeLevelError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+			if err := SetNamedLevel(req.Name, level, ttl); err != nil {
+				writeLevelError(w, http.StatusNotFound, err)
+				return
+			}
+			writeLevelResponse(w, GetNamedLevels(req.Name))
+		default:
+			writeLevelError(w, http.StatusMethodNotAllowed, errors.New("only GET and PUT are supported"))
+		}
+	})
+}
+
+func writeLevelResponse(w http.ResponseWriter, infos []LevelInfo) {
+	if infos == nil {
+		infos = []LevelInfo{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(infos)
+}
+
+func writeLevelError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/go-halo/json"
+)
+
+func TestLevelHandler(t *testing.T) {
+	order := NewConsole()
+	order.SetLevel(InfoLevel)
+	payment := NewConsole()
+	payment.SetLevel(WarnLevel)
+	Register("order", order)
+	Register("payment", payment)
+	defer Unregister("order")
+	defer Unregister("payment")
+
+	srv := httptest.NewServer(LevelHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	var infos []LevelInfo
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&infos))
+	resp.Body.Close()
+	assert.Equal(t, []LevelInfo{
+		{Name: "order", Level: "info"},
+		{Name: "payment", Level: "warn"},
+	}, infos)
+
+	resp, err = http.Get(srv.URL + "?name=unknown")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"name":"order","level":"debug"}`))
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, DebugLevel, order.GetLevel())
+	assert.Equal(t, WarnLevel, payment.GetLevel())
+
+	req, _ = http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"level":"bad"}`))
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"level":"error"}`))
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, ErrorLevel, order.GetLevel())
+	assert.Equal(t, ErrorLevel, payment.GetLevel())
+}
+
+func TestSetNamedLevelTTL(t *testing.T) {
+	log := NewConsole()
+	log.SetLevel(InfoLevel)
+	Register("ttl", log)
+	defer Unregister("ttl")
+
+	assert.NoError(t, SetNamedLevel("ttl", DebugLevel, time.Millisecond*50))
+	assert.Equal(t, DebugLevel, log.GetLevel())
+	infos := GetNamedLevels("ttl")
+	assert.NotNil(t, infos[0].ExpireAt)
+	// TTL 未到期时再次修改，恢复的仍是最初的级别
+	assert.NoError(t, SetNamedLevel("ttl", WarnLevel, time.Millisecond*100))
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == InfoLevel
+	}, time.Second, time.Millisecond*10)
+	assert.Nil(t, GetNamedLevels("ttl")[0].ExpireAt)
+
+	assert.Error(t, SetNamedLevel("unknown", DebugLevel, 0))
+}
+
+func TestSetNamedLevelTTLModule(t *testing.T) {
+	defer SetModuleLevels(nil)
+	root := NewConsole()
+	root.SetLevel(InfoLevel)
+	order := root.Named("order")
+	Register("ttl-order", order)
+	defer Unregister("ttl-order")
+
+	// 到期后删除临时添加的模块规则，继续跟随根 Logger 的级别
+	assert.NoError(t, SetNamedLevel("ttl-order", DebugLevel, time.Millisecond*50))
+	assert.Equal(t, DebugLevel, order.GetLevel())
+	assert.Eventually(t, func() bool {
+		return len(ModuleLevels()) == 0
+	}, time.Second, time.Millisecond*10)
+	root.SetLevel(WarnLevel)
+	assert.Equal(t, WarnLevel, order.GetLevel())
+
+	// 原来有模块规则时恢复规则的级别
+	SetModuleLevel("order", ErrorLevel)
+	assert.NoError(t, SetNamedLevel("ttl-order", DebugLevel, time.Millisecond*50))
+	assert.Eventually(t, func() bool {
+		return order.GetLevel() == ErrorLevel
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, map[string]Level{"order": ErrorLevel}, ModuleLevels())
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrReloadNotSupported 只有 New 创建的 Logger 支持 Reload
+var ErrReloadNotSupported = errors.New("logger does not support reload")
+
+// sinkState 根据 Options 创建的输出，Reload 时整体替换
+type sinkState struct {
+	core    zapcore.Core
+	async   []*asyncWriter
+	remote  []*remoteWriter
+	closers []io.Closer // 按创建顺序记录，外层的输出后创建
+
+	mu     sync.RWMutex // 写日志时持有读锁，关闭时等待正在进行的写入完成
+	closed bool
+}
+
+// close 等待正在进行的写入完成后关闭文件和连接
+func (s *sinkState) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	_ = s.core.Sync()
+	// 先关闭外层的异步队列，再关闭底层的文件和连接
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		_ = s.closers[i].Close()
+	}
+}
+
+// reloadableCore 可以在运行时替换底层输出的 zapcore.Core
+// With 派生的 core 记录附加字段，底层输出替换后重新附加到新的 core 上
+type reloadableCore struct {
+	state  *atomic.Pointer[sinkState]
+	fields []zapcore.Field
+	cache  atomic.Pointer[derivedCore]
+}
+
+// derivedCore 缓存附加字段后的 core，避免每次写日志都执行 With
+type derivedCore struct {
+	state *sinkState
+	core  zapcore.Core
+}
+
+func newReloadableCore(state *sinkState) *reloadableCore {
+	c := &reloadableCore{
+		state: &atomic.Pointer[sinkState]{},
+	}
+	c.state.Store(state)
+	return c
+}
+
+func (c *reloadableCore) current() zapcore.Core {
+	return c.coreOf(c.state.Load())
+}
+
+// acquire 返回当前的 sinkState 并持有读锁，Reload 关闭旧的 sinkState 时会等待读锁释放
+func (c *reloadableCore) acquire() *sinkState {
+	for {
+		state := c.state.Load()
+		state.mu.RLock()
+		if !state.closed {
+			return state
+		}
+		// 已经被替换并关闭，重新获取
+		state.mu.RUnlock()
+	}
+}
+
+func (c *reloadableCore) coreOf(state *sinkState) zapcore.Core {
+	if len(c.fields) == 0 {
+		return state.core
+	}
+	if d := c.cache.Load(); d != nil && d.state == state {
+		return d.core
+	}
+	d := &derivedCore{
+		state: state,
+		core:  state.core.With(c.fields),
+	}
+	c.cache.Store(d)
+	return d.core
+}
+
+func (c *reloadableCore) Enabled(level Level) bool {
+	return c.current().Enabled(level)
+}
+
+func (c *reloadableCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &reloadableCore{
+		state:  c.state,
+		fields: all,
+	}
+}
+
+// Check 只判断级别，在 Write 中持有 sinkState 的读锁后再交给底层输出，
+// 避免 Check 和 Write 之间 Reload 关闭了底层输出
+func (c *reloadableCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *reloadableCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	state := c.acquire()
+	defer state.mu.RUnlock()
+	// 底层的 Tee 在 Check 中按 Sink 级别过滤，采样也在 Check 中完成
+	if ce := c.coreOf(state).Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *reloadableCore) Sync() error {
+	state := c.acquire()
+	defer state.mu.RUnlock()
+	return state.core.Sync()
+}
+
+// Reload 使用新的配置替换 Logger 的输出、采样、脱敏等配置，并修改日志级别
+// 已经通过 With 派生的 Logger 同样生效，只支持 New 创建的 Logger
+func Reload(l Logger, opt *Options) error {
+	lg, ok := l.(*logger)
+	if !ok || lg.root == nil {
+		return ErrReloadNotSupported
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	defaultOptions(opt)
+	lg.atomicLevel.SetLevel(opt.Level)
+	old := lg.root.state.Swap(newSinkState(opt))
+	old.close()
+	return nil
+}
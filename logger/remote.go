@@ -0,0 +1,282 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRemoteFlushTimeout 远程输出在 FlushTimeout 内没有发送完成
+var ErrRemoteFlushTimeout = errors.New("remote log flush timeout")
+
+// RemoteFormat 远程输出格式
+type RemoteFormat string
+
+const (
+	// RemoteFormatRaw 原样发送编码后的日志，流式连接按行分隔
+	RemoteFormatRaw RemoteFormat = ""
+	// RemoteFormatRFC5424 syslog RFC 5424 格式，流式连接使用 RFC 6587 octet-counting 分隔
+	RemoteFormatRFC5424 RemoteFormat = "rfc5424"
+)
+
+const (
+	defaultRemoteBufferSize = 4096
+	defaultRemoteTimeout    = time.Second * 3
+	defaultRemoteMaxBackoff = time.Second * 10
+	remoteMinBackoff        = time.Millisecond * 100
+	syslogFacilityUser      = 1
+)
+
+// RemoteOptions 远程输出配置，支持 tcp、udp、unix、unixgram
+type RemoteOptions struct {
+	Network      string        // tcp、udp、unix、unixgram
+	Addr         string        // 例如 127.0.0.1:514、/var/run/agent.sock
+	Format       RemoteFormat  // 默认: RemoteFormatRaw
+	Facility     int           // syslog facility，默认: 1 (user)
+	AppName      string        // syslog APP-NAME，默认: 进程名
+	BufferSize   int           // 待发送日志的最大条数，超出后丢弃最早的日志，默认: 4096
+	DialTimeout  time.Duration // 默认: 3s
+	WriteTimeout time.Duration // 默认: 3s
+	MaxBackoff   time.Duration // 断开后重连的最大间隔，默认: 10s
+	FlushTimeout time.Duration // Flush 等待发送完成的最长时间，默认: 3s
+}
+
+// remoteWriter 后台协程负责连接和发送，断开期间日志缓存在有界队列中
+type remoteWriter struct {
+	opt      RemoteOptions
+	stream   bool
+	hostname string
+	pid      int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   [][]byte
+	sending bool
+	closed  bool
+	dropped uint64
+	quit    chan struct{}
+	conn    net.Conn // 只在后台协程中使用
+}
+
+func newRemoteWriter(opt *RemoteOptions) *remoteWriter {
+	w := &remoteWriter{
+		opt:  *opt,
+		quit: make(chan struct{}),
+		pid:  os.Getpid(),
+	}
+	if w.opt.Facility == 0 {
+		w.opt.Facility = syslogFacilityUser
+	}
+	if w.opt.AppName == "" {
+		w.opt.AppName = filepath.Base(os.Args[0])
+	}
+	if w.opt.BufferSize <= 0 {
+		w.opt.BufferSize = defaultRemoteBufferSize
+	}
+	if w.opt.DialTimeout <= 0 {
+		w.opt.DialTimeout = defaultRemoteTimeout
+	}
+	if w.opt.WriteTimeout <= 0 {
+		w.opt.WriteTimeout = defaultRemoteTimeout
+	}
+	if w.opt.MaxBackoff <= 0 {
+		w.opt.MaxBackoff = defaultRemoteMaxBackoff
+	}
+	if w.opt.FlushTimeout <= 0 {
+		w.opt.FlushTimeout = defaultRemoteTimeout
+	}
+	switch w.opt.Network {
+	case "udp", "udp4", "udp6", "unixgram":
+	default:
+		w.stream = true
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	w.hostname = hostname
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+// WriteLevel 格式化后放入发送队列，不会阻塞
+func (w *remoteWriter) WriteLevel(level Level, p []byte) error {
+	msg := w.format(level, p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queue = append(w.queue, msg)
+	w.trimLocked()
+	w.cond.Broadcast()
+	return nil
+}
+
+// trimLocked 超出缓存大小时丢弃最早的日志
+func (w *remoteWriter) trimLocked() {
+	if n := len(w.queue) - w.opt.BufferSize; n > 0 {
+		w.queue = w.queue[n:]
+		atomic.AddUint64(&w.dropped, uint64(n))
+	}
+}
+
+func (w *remoteWriter) format(level Level, p []byte) []byte {
+	p = bytes.TrimRight(p, "\n")
+	buf := &bytes.Buffer{}
+	if w.opt.Format == RemoteFormatRFC5424 {
+		msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+			w.opt.Facility*8+syslogSeverity(level),
+			currentTime().Format("2006-01-02T15:04:05.000000Z07:00"),
+			w.hostname, w.opt.AppName, w.pid, p)
+		if w.stream {
+			buf.WriteString(strconv.Itoa(len(msg)))
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(msg)
+		return buf.Bytes()
+	}
+	buf.Write(p)
+	if w.stream {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// syslogSeverity 日志级别对应的 syslog severity
+func syslogSeverity(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 7
+	case InfoLevel:
+		return 6
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	case DPanicLevel:
+		return 2
+	case PanicLevel:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (w *remoteWriter) run() {
+	backoff := remoteMinBackoff
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			w.closeConn()
+			return
+		}
+		batch := w.queue
+		w.queue = nil
+		w.sending = true
+		w.mu.Unlock()
+
+		n, err := w.send(batch)
+
+		w.mu.Lock()
+		w.sending = false
+		if err != nil {
+			// 未发送的日志放回队列头部
+			w.queue = append(batch[n:len(batch):len(batch)], w.queue...)
+			w.trimLocked()
+		}
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		if err == nil {
+			backoff = remoteMinBackoff
+			continue
+		}
+		select {
+		case <-w.quit:
+			w.closeConn()
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > w.opt.MaxBackoff {
+			backoff = w.opt.MaxBackoff
+		}
+	}
+}
+
+// send 返回成功发送的条数
+func (w *remoteWriter) send(batch [][]byte) (int, error) {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.opt.Network, w.opt.Addr, w.opt.DialTimeout)
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+	for i, msg := range batch {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.opt.WriteTimeout))
+		if _, err := w.conn.Write(msg); err != nil {
+			w.closeConn()
+			return i, err
+		}
+	}
+	return len(batch), nil
+}
+
+func (w *remoteWriter) closeConn() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// Sync 等待队列中的日志发送完成，最多等待 FlushTimeout
+func (w *remoteWriter) Sync() error {
+	deadline := time.Now().Add(w.opt.FlushTimeout)
+	timer := time.AfterFunc(w.opt.FlushTimeout, func() {
+		w.mu.Lock()
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) > 0 || w.sending {
+		if !time.Now().Before(deadline) {
+			return ErrRemoteFlushTimeout
+		}
+		w.cond.Wait()
+	}
+	return nil
+}
+
+// Close 等待日志发送完成后关闭连接，超过 FlushTimeout 未发送的日志会被丢弃
+func (w *remoteWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	err := w.Sync()
+	close(w.quit)
+	return err
+}
+
+// Dropped 返回丢弃的日志条数
+func (w *remoteWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
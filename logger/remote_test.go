@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteTCPSyslog(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	log := New(&Options{
+		Sinks: []Sink{{
+			Remote: &RemoteOptions{
+				Network: "tcp",
+				Addr:    ln.Addr().String(),
+				Format:  RemoteFormatRFC5424,
+				AppName: "halo-test",
+			},
+		}},
+	})
+	log.Info("info msg")
+	log.Error("error msg")
+	log.Flush()
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for _, expect := range []string{"<14>1 ", "<11>1 "} {
+		size, err := r.ReadString(' ')
+		assert.NoError(t, err)
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		assert.NoError(t, err)
+		msg := make([]byte, n)
+		_, err = io.ReadFull(r, msg)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(msg), expect), string(msg))
+		assert.Contains(t, string(msg), " halo-test ")
+	}
+}
+
+func TestRemoteUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	log := New(&Options{
+		Sinks: []Sink{{
+			Remote: &RemoteOptions{Network: "udp", Addr: pc.LocalAddr().String()},
+		}},
+	})
+	log.Warn("udp msg")
+	log.Flush()
+
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second * 3))
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), `"msg":"udp msg"`)
+}
+
+func TestRemoteReconnect(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "agent.sock")
+	log := New(&Options{
+		Sinks: []Sink{{
+			Remote: &RemoteOptions{
+				Network:    "unix",
+				Addr:       addr,
+				MaxBackoff: time.Millisecond * 50,
+			},
+		}},
+	})
+	log.Info("msg 1")
+	log.Info("msg 2")
+	time.Sleep(time.Millisecond * 100)
+
+	ln, err := net.Listen("unix", addr)
+	assert.NoError(t, err)
+	defer ln.Close()
+	log.Info("msg 3")
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 3))
+	r := bufio.NewReader(conn)
+	for i := 1; i <= 3; i++ {
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Contains(t, line, `"msg":"msg `+strconv.Itoa(i)+`"`)
+	}
+	assert.Equal(t, uint64(0), RemoteDropped(log))
+}
+
+func TestRemoteBufferFull(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "none.sock")
+	log := New(&Options{
+		Sinks: []Sink{{
+			Remote: &RemoteOptions{
+				Network:      "unix",
+				Addr:         addr,
+				BufferSize:   2,
+				FlushTimeout: time.Millisecond * 50,
+			},
+		}},
+	})
+	for i := 0; i < 5; i++ {
+		log.Infof("msg %d", i)
+	}
+	assert.Eventually(t, func() bool {
+		return RemoteDropped(log) == 3
+	}, time.Second, time.Millisecond*10)
+	assert.NoError(t, Reload(log, &Options{Sinks: []Sink{{Writer: &slowWriter{}}}}))
+}
@@ -1,20 +1,164 @@
 package logger
 
 import (
-	"gopkg.in/natefinch/lumberjack.v2"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 )
 
+// rotateWriter 按时间周期和文件大小切割日志，以追加方式打开文件，新建的文件权限为 0600
+// 切割文件的压缩和清理由 backupCleaner 在后台完成，Close 时停止。
+// 不使用 lumberjack，它打开文件时启动的后台协程在 Close 后不会退出，每次 Reload 都会泄漏一个协程
 type rotateWriter struct {
-	*lumberjack.Logger
-	date string
+	filename   string
+	file       *os.File // 当前打开的文件，第一次写入时打开
+	interval   time.Duration
+	timeFormat string
+	utc        bool
+	maxSize    int64 // 单个文件最大字节数
+	mu         sync.Mutex
+	period     time.Time // 当前文件所属周期的开始时间
+	size       int64     // 当前文件大小
+	cleaner    *backupCleaner
+	closed     bool
 }
 
-// Write 重写 Write，支持每日切割
+func newRotateWriter(opt *Options, filename string) *rotateWriter {
+	rw := &rotateWriter{
+		filename:   filename,
+		interval:   opt.RotateInterval,
+		timeFormat: opt.BackupTimeFormat,
+		utc:        opt.UTC,
+		maxSize:    int64(opt.MaxSizeMB) * 1024 * 1024,
+		cleaner:    newBackupCleaner(filename, opt),
+	}
+	fstat, err := os.Stat(filename)
+	if err == nil && fstat.Size() > 0 {
+		// 记录文件最后修改位置
+		rw.period = rw.periodOf(fstat.ModTime())
+		rw.size = fstat.Size()
+	}
+	// 启动时清理一次历史文件
+	rw.cleaner.trigger(currentTime())
+	return rw
+}
+
+// Write 重写 Write，支持按时间周期和文件大小切割
 func (r *rotateWriter) Write(p []byte) (n int, err error) {
-	d := currentTime().Format(backupDayFormat)
-	if r.date != "" && r.date != d {
-		_ = r.Logger.Rotate()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		// 关闭后不再写入，避免重新打开文件
+		return 0, os.ErrClosed
+	}
+	now := currentTime()
+	period := r.periodOf(now)
+	if !r.period.IsZero() && !period.Equal(r.period) {
+		r.rotate(now)
+	} else if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		r.rotate(now)
+	}
+	r.period = period
+	if r.file == nil {
+		if err = r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// open 以追加方式打开日志文件，目录不存在时创建
+func (r *rotateWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0755); err != nil {
+		return fmt.Errorf("can't make directories for new logfile: %w", err)
+	}
+	f, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open logfile: %w", err)
+	}
+	r.file = f
+	return nil
+}
+
+func (r *rotateWriter) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Close 关闭文件并停止后台清理协程
+func (r *rotateWriter) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	err := r.closeFile()
+	r.mu.Unlock()
+	r.cleaner.close()
+	return err
+}
+
+// rotate 将当前文件重命名为所属周期的备份文件，并在后台压缩和清理
+func (r *rotateWriter) rotate(now time.Time) {
+	if err := r.closeFile(); err != nil {
+		return
+	}
+	r.size = 0
+	if _, err := os.Stat(r.filename); err != nil {
+		return
+	}
+	backup := backupName(r.filename, r.period.Format(r.timeFormat))
+	if err := os.Rename(r.filename, backup); err != nil {
+		return
+	}
+	r.cleaner.trigger(now)
+}
+
+// periodOf 返回 t 所属切割周期的开始时间，周期从每天 0 点开始计算
+func (r *rotateWriter) periodOf(t time.Time) time.Time {
+	if r.utc {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	if r.interval >= time.Hour*24 {
+		return day
+	}
+	return day.Add(t.Sub(day) / r.interval * r.interval)
+}
+
+// backupName 返回切割文件名 ${name}-${timeStr}${ext}，同一周期内多次切割时增加序号
+func backupName(name string, timeStr string) string {
+	dir := filepath.Dir(name)
+	filename := filepath.Base(name)
+	ext := filepath.Ext(filename)
+	prefix := filename[:len(filename)-len(ext)]
+	backup := filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, timeStr, ext))
+	for i := 1; ; i++ {
+		if !backupExists(backup) {
+			return backup
+		}
+		backup = filepath.Join(dir, fmt.Sprintf("%s-%s.%d%s", prefix, timeStr, i, ext))
+	}
+}
+
+// backupExists 判断切割文件或其压缩文件是否存在
+func backupExists(backup string) bool {
+	for _, name := range []string{backup, backup + gzipExt, backup + zstdExt} {
+		if _, err := os.Stat(name); err == nil {
+			return true
+		}
 	}
-	r.date = d
-	return r.Logger.Write(p)
+	return false
 }
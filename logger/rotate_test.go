@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRotateTestOptions() *Options {
+	return &Options{RotateInterval: time.Hour * 24, BackupTimeFormat: backupDayFormat}
+}
+
+func TestRotateWriterAppend(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "nested", "append.log")
+	// 目录不存在时第一次写入自动创建
+	w := newRotateWriter(newRotateTestOptions(), filename)
+	_, err := w.Write([]byte("line1\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	info, err := os.Stat(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 重新打开时追加写入，并从已有文件大小开始计算
+	w = newRotateWriter(newRotateTestOptions(), filename)
+	assert.Equal(t, int64(6), w.size)
+	_, err = w.Write([]byte("line2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\nline2\n", string(data))
+}
+
+func TestRotateWriterSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "size.log")
+	w := newRotateWriter(newRotateTestOptions(), filename)
+	w.maxSize = 100
+	line := strings.Repeat("a", 39) + "\n"
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	// 每个文件最多写入 2 行，同一周期内多次切割时增加序号
+	period := w.period.Format(backupDayFormat)
+	files := []string{
+		filepath.Join(dir, "size-"+period+".log"),
+		filepath.Join(dir, "size-"+period+".1.log"),
+		filepath.Join(dir, "size-"+period+".2.log"),
+		filepath.Join(dir, "size-"+period+".3.log"),
+		filename,
+	}
+	total := 0
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(data), 100)
+		total += strings.Count(string(data), "\n")
+	}
+	assert.Equal(t, 10, total)
+}
+
+func TestRotateWriterClose(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "close.log")
+	w := newRotateWriter(newRotateTestOptions(), filename)
+	_, err := w.Write([]byte("line\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close())
+
+	// 关闭后不再打开文件写入，后台清理协程已经退出
+	assert.NoError(t, os.Remove(filename))
+	_, err = w.Write([]byte("after close\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+	_, err = os.Stat(filename)
+	assert.True(t, os.IsNotExist(err))
+	select {
+	case <-w.cleaner.done:
+	default:
+		t.Fatal("cleaner not stopped")
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultSamplerTick = time.Second
+
+// SamplerOptions 日志采样配置，用于限制短时间内大量重复的日志
+// 每个统计窗口结束后，会按消息输出一条 "suppressed N similar messages" 汇总日志
+type SamplerOptions struct {
+	Tick        time.Duration // 统计窗口，默认: 1s
+	First       int           // 每个窗口内相同级别和内容的日志先输出 First 条
+	Thereafter  int           // 超过 First 条后每 Thereafter 条输出一条，0 表示全部丢弃
+	LevelLimits map[Level]int // 每个窗口内各级别最多输出的日志条数，不配置则不限制
+}
+
+// WithSampler 返回开启日志采样的 zap.Option，可用于 NewConsole
+func WithSampler(opt *SamplerOptions) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newSamplerCore(core, opt)
+	})
+}
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+// sampler 采样状态，With 派生的 core 共享同一个 sampler
+type sampler struct {
+	root        zapcore.Core // 用来输出汇总日志
+	tick        time.Duration
+	first       int
+	thereafter  int
+	levelLimits map[Level]int
+
+	mu          sync.Mutex
+	start       time.Time // 当前窗口开始时间
+	counts      map[sampleKey]int
+	levelCounts map[Level]int
+	suppressed  map[sampleKey]int
+	scheduled   bool
+}
+
+type samplerCore struct {
+	zapcore.Core
+	s *sampler
+}
+
+func newSamplerCore(core zapcore.Core, opt *SamplerOptions) zapcore.Core {
+	tick := opt.Tick
+	if tick <= 0 {
+		tick = defaultSamplerTick
+	}
+	s := &sampler{
+		root:        core,
+		tick:        tick,
+		first:       opt.First,
+		thereafter:  opt.Thereafter,
+		levelLimits: opt.LevelLimits,
+		counts:      make(map[sampleKey]int),
+		levelCounts: make(map[Level]int),
+		suppressed:  make(map[sampleKey]int),
+	}
+	return &samplerCore{Core: core, s: s}
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplerCore{
+		Core: c.Core.With(fields),
+		s:    c.s,
+	}
+}
+
+func (c *samplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.s.allow(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// allow 判断日志是否输出，Panic 和 Fatal 级别不采样
+func (s *sampler) allow(ent zapcore.Entry) bool {
+	if ent.Level > ErrorLevel {
+		return true
+	}
+	now := time.Now()
+	key := sampleKey{level: ent.Level, msg: ent.Message}
+
+	s.mu.Lock()
+	var summary map[sampleKey]int
+	if now.Sub(s.start) >= s.tick {
+		summary = s.rollLocked(now)
+	}
+	allowed := s.allowLocked(key)
+	if !allowed {
+		s.suppressed[key]++
+		if !s.scheduled {
+			// 窗口结束后输出汇总，避免之后没有日志时汇总丢失
+			s.scheduled = true
+			time.AfterFunc(s.start.Add(s.tick).Sub(now), s.onTick)
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeSummary(summary, now)
+	return allowed
+}
+
+func (s *sampler) allowLocked(key sampleKey) bool {
+	if s.first > 0 || s.thereafter > 0 {
+		s.counts[key]++
+		n := s.counts[key]
+		if n > s.first && (s.thereafter <= 0 || (n-s.first)%s.thereafter != 0) {
+			return false
+		}
+	}
+	if limit, ok := s.levelLimits[key.level]; ok {
+		if s.levelCounts[key.level] >= limit {
+			return false
+		}
+		s.levelCounts[key.level]++
+	}
+	return true
+}
+
+// rollLocked 开始新的统计窗口，返回上一个窗口被丢弃的日志数量
+func (s *sampler) rollLocked(now time.Time) map[sampleKey]int {
+	s.start = now
+	if len(s.counts) > 0 {
+		s.counts = make(map[sampleKey]int)
+	}
+	if len(s.levelCounts) > 0 {
+		s.levelCounts = make(map[Level]int)
+	}
+	if len(s.suppressed) == 0 {
+		return nil
+	}
+	summary := s.suppressed
+	s.suppressed = make(map[sampleKey]int)
+	return summary
+}
+
+func (s *sampler) onTick() {
+	now := time.Now()
+	s.mu.Lock()
+	s.scheduled = false
+	var summary map[sampleKey]int
+	if now.Sub(s.start) >= s.tick {
+		summary = s.rollLocked(now)
+	}
+	s.mu.Unlock()
+	s.writeSummary(summary, now)
+}
+
+func (s *sampler) writeSummary(summary map[sampleKey]int, now time.Time) {
+	for key, n := range summary {
+		ent := zapcore.Entry{
+			Level:   key.level,
+			Time:    now,
+			Message: fmt.Sprintf("suppressed %s similar messages", formatCount(n)),
+		}
+		if ce := s.root.Check(ent, nil); ce != nil {
+			ce.Write(zap.String("sampled_msg", key.msg), zap.Int("suppressed", n))
+		}
+	}
+}
+
+// formatCount 千分位格式化，例如 4312 -> 4,312
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
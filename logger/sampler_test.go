@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	w := &slowWriter{}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: w}},
+		Sampler: &SamplerOptions{
+			Tick:       time.Millisecond * 200,
+			First:      3,
+			Thereafter: 10,
+		},
+	})
+	for i := 0; i < 103; i++ {
+		log.Error("downstream unavailable")
+	}
+	log.Info("other message")
+	assert.Equal(t, 14, w.lines())
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "suppressed 90 similar messages")
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestSamplerLevelLimits(t *testing.T) {
+	w := &slowWriter{}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: w}},
+		Sampler: &SamplerOptions{
+			Tick:        time.Hour,
+			LevelLimits: map[Level]int{InfoLevel: 5},
+		},
+	})
+	for i := 0; i < 10; i++ {
+		log.Infof("info %d", i)
+		log.Warnf("warn %d", i)
+	}
+	assert.Equal(t, 15, w.lines())
+}
+
+func TestWithSampler(t *testing.T) {
+	w := &slowWriter{}
+	log := New(&Options{Sinks: []Sink{{Writer: w}}}, WithSampler(&SamplerOptions{
+		Tick:  time.Millisecond * 50,
+		First: 1,
+	}))
+	for i := 0; i < 5; i++ {
+		log.Info("option sampled")
+	}
+	assert.Equal(t, 1, w.lines())
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "suppressed 4 similar messages")
+	}, time.Second, time.Millisecond*10)
+	assert.Contains(t, w.String(), `"sampled_msg":"option sampled"`)
+	assert.Contains(t, w.String(), `"suppressed":4`)
+}
+
+func TestFormatCount(t *testing.T) {
+	assert.Equal(t, "12", formatCount(12))
+	assert.Equal(t, "4,312", formatCount(4312))
+	assert.Equal(t, "1,234,567", formatCount(1234567))
+}
@@ -0,0 +1,389 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler 使用 Logger 输出的 slog.Handler
+type slogHandler struct {
+	log    Logger
+	groups []string // 还没有输出字段的分组，有字段时才创建，避免输出空分组
+}
+
+// NewSlogHandler 创建由 Logger 输出的 slog.Handler，日志会经过 Logger 配置的 Sink、切割等处理
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{log: l}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.log.GetLevel() <= fromSlogLevel(level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]zap.Field, 0, r.NumAttrs()+len(h.groups))
+	r.Attrs(func(a slog.Attr) bool {
+		if f, ok := attrToField(a); ok {
+			fields = append(fields, f)
+		}
+		return true
+	})
+	if len(fields) > 0 && len(h.groups) > 0 {
+		fields = append(groupFields(h.groups), fields...)
+	}
+	level := fromSlogLevel(r.Level)
+	if l, ok := h.log.(*logger); ok {
+		l.logRecord(ctx, level, r, fields)
+		return nil
+	}
+	switch {
+	case level >= ErrorLevel:
+		h.log.ErrorCtx(ctx, r.Message, fields...)
+	case level >= WarnLevel:
+		h.log.WarnCtx(ctx, r.Message, fields...)
+	case level >= InfoLevel:
+		h.log.InfoCtx(ctx, r.Message, fields...)
+	default:
+		h.log.DebugCtx(ctx, r.Message, fields...)
+	}
+	return nil
+}
+
+// logRecord 输出 slog.Record，调用位置使用 Record 中记录的 PC，而不是 Handle 所在的位置
+func (l *logger) logRecord(ctx context.Context, level Level, r slog.Record, fields []zap.Field) {
+	if level > ErrorLevel {
+		level = ErrorLevel
+	}
+	if !l.checkLevel(level) {
+		return
+	}
+	ce := l.log.Check(level, r.Message)
+	if ce == nil {
+		return
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	ce.Write(append(fields, ContextFields(ctx)...)...)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		if f, ok := attrToField(a); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	fields = append(groupFields(h.groups), fields...)
+	return &slogHandler{log: h.log.With(fields...)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &slogHandler{log: h.log, groups: append(groups, name)}
+}
+
+func groupFields(groups []string) []zap.Field {
+	fields := make([]zap.Field, 0, len(groups))
+	for _, g := range groups {
+		fields = append(fields, zap.Namespace(g))
+	}
+	return fields
+}
+
+// attrToField slog.Attr 转换为 zap.Field，空 Attr 返回 false
+func attrToField(a slog.Attr) (zap.Field, bool) {
+	v := a.Value.Resolve()
+	if a.Key == "" && v.Kind() != slog.KindGroup {
+		if v.Any() == nil {
+			return zap.Skip(), false
+		}
+	}
+	switch v.Kind() {
+	case slog.KindBool:
+		return zap.Bool(a.Key, v.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(a.Key, v.Duration()), true
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, v.Float64()), true
+	case slog.KindInt64:
+		return zap.Int64(a.Key, v.Int64()), true
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, v.Uint64()), true
+	case slog.KindString:
+		return zap.String(a.Key, v.String()), true
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time()), true
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return zap.Skip(), false
+		}
+		if a.Key == "" {
+			return zap.Inline(slogGroup(attrs)), true
+		}
+		return zap.Object(a.Key, slogGroup(attrs)), true
+	default:
+		if err, ok := v.Any().(error); ok {
+			return zap.NamedError(a.Key, err), true
+		}
+		return zap.Any(a.Key, v.Any()), true
+	}
+}
+
+// slogGroup 将 slog 分组编码为 json 对象
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, a := range g {
+		if f, ok := attrToField(a); ok {
+			f.AddTo(enc)
+		}
+	}
+	return nil
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		// DPanic、Panic、Fatal 高于 Error
+		return slog.LevelError + slog.Level(level-ErrorLevel)
+	}
+}
+
+// slogLogger 使用 slog.Handler 输出的 Logger
+type slogLogger struct {
+	atomicLevel zap.AtomicLevel
+	handler     slog.Handler
+	callerSkip  int
+	module      *moduleLevel // Named 创建的 Logger 才有，输出为 logger 字段
+}
+
+// NewWithSlog 使用 slog.Handler 创建 Logger，默认级别: DebugLevel，同时受 Handler.Enabled 限制
+func NewWithSlog(h slog.Handler) Logger {
+	return &slogLogger{
+		atomicLevel: zap.NewAtomicLevelAt(DebugLevel),
+		handler:     h,
+	}
+}
+
+func (l *slogLogger) With(fields ...zap.Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	h := l.handler
+	var attrs []slog.Attr
+	for _, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			h = h.WithAttrs(attrs).WithGroup(f.Key)
+			attrs = nil
+			continue
+		}
+		attrs = append(attrs, fieldToAttrs(f)...)
+	}
+	if len(attrs) > 0 {
+		h = h.WithAttrs(attrs)
+	}
+	c := *l
+	c.handler = h
+	return &c
+}
+
+func (l *slogLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	c := *l
+	c.module = childModule(l.module, name)
+	return &c
+}
+
+func (l *slogLogger) WithContext(ctx context.Context) Logger {
+	return l.With(ContextFields(ctx)...)
+}
+
+func (l *slogLogger) Debug(msg string, fields ...zap.Field) {
+	l.log(context.Background(), DebugLevel, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields ...zap.Field) {
+	l.log(context.Background(), InfoLevel, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields ...zap.Field) {
+	l.log(context.Background(), WarnLevel, msg, fields)
+}
+
+func (l *slogLogger) Error(msg string, fields ...zap.Field) {
+	l.log(context.Background(), ErrorLevel, msg, fields)
+}
+
+func (l *slogLogger) DPanic(msg string, fields ...zap.Field) {
+	l.log(context.Background(), DPanicLevel, msg, fields)
+}
+
+func (l *slogLogger) Panic(msg string, fields ...zap.Field) {
+	l.log(context.Background(), PanicLevel, msg, fields)
+	panic(msg)
+}
+
+func (l *slogLogger) Fatal(msg string, fields ...zap.Field) {
+	l.log(context.Background(), FatalLevel, msg, fields)
+	os.Exit(1)
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.log(context.Background(), DebugLevel, getMessage(format, args), nil)
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.log(context.Background(), InfoLevel, getMessage(format, args), nil)
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.log(context.Background(), WarnLevel, getMessage(format, args), nil)
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.log(context.Background(), ErrorLevel, getMessage(format, args), nil)
+}
+
+func (l *slogLogger) DPanicf(format string, args ...interface{}) {
+	l.log(context.Background(), DPanicLevel, getMessage(format, args), nil)
+}
+
+func (l *slogLogger) Panicf(format string, args ...interface{}) {
+	msg := getMessage(format, args)
+	l.log(context.Background(), PanicLevel, msg, nil)
+	panic(msg)
+}
+
+func (l *slogLogger) Fatalf(format string, args ...interface{}) {
+	l.log(context.Background(), FatalLevel, getMessage(format, args), nil)
+	os.Exit(1)
+}
+
+func (l *slogLogger) DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	l.log(ctx, DebugLevel, msg, append(fields, ContextFields(ctx)...))
+}
+
+func (l *slogLogger) InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	l.log(ctx, InfoLevel, msg, append(fields, ContextFields(ctx)...))
+}
+
+func (l *slogLogger) WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	l.log(ctx, WarnLevel, msg, append(fields, ContextFields(ctx)...))
+}
+
+func (l *slogLogger) ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	l.log(ctx, ErrorLevel, msg, append(fields, ContextFields(ctx)...))
+}
+
+func (l *slogLogger) Flush() {
+}
+
+func (l *slogLogger) SetLevel(level Level) {
+	if l.module != nil {
+		SetModuleLevel(l.module.module, level)
+		return
+	}
+	l.atomicLevel.SetLevel(level)
+}
+
+func (l *slogLogger) GetLevel() Level {
+	if l.module != nil {
+		if level, ok := l.module.resolve(); ok {
+			return level
+		}
+	}
+	return l.atomicLevel.Level()
+}
+
+func (l *slogLogger) log(ctx context.Context, level Level, msg string, fields []zap.Field) {
+	if l.GetLevel() > level {
+		return
+	}
+	slogLevel := toSlogLevel(level)
+	if !l.handler.Enabled(ctx, slogLevel) {
+		return
+	}
+	var pcs [1]uintptr
+	// 跳过 runtime.Callers、log 和 Logger 方法
+	runtime.Callers(3+l.callerSkip, pcs[:])
+	r := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+	if l.module != nil {
+		r.AddAttrs(slog.String("logger", l.module.module))
+	}
+	r.AddAttrs(fieldsToAttrs(fields)...)
+	_ = l.handler.Handle(ctx, r)
+}
+
+// fieldsToAttrs zap.Field 转换为 slog.Attr，zap.Namespace 之后的字段放到对应分组中
+func fieldsToAttrs(fields []zap.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(fieldsToAttrs(fields[i+1:])...)})
+		}
+		attrs = append(attrs, fieldToAttrs(f)...)
+	}
+	return attrs
+}
+
+// fieldToAttrs 通过 MapObjectEncoder 获取 zap.Field 的值
+func fieldToAttrs(f zap.Field) []slog.Attr {
+	if f.Type == zapcore.SkipType {
+		return nil
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	attrs := make([]slog.Attr, 0, len(enc.Fields))
+	for k, v := range enc.Fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	return attrs
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(&Options{
+		Sinks: []Sink{{Writer: buf}},
+	})
+	sl := slog.New(NewSlogHandler(log))
+
+	sl.Debug("debug msg")
+	assert.Empty(t, buf.String())
+
+	sl.With("a", 1).WithGroup("g").Info("info msg", "b", 2, slog.Group("sub", "c", "x"))
+	assert.Contains(t, buf.String(), `"msg":"info msg","a":1,"g":{"b":2,"sub":{"c":"x"}}`)
+
+	buf.Reset()
+	sl.WithGroup("empty").Warn("warn msg")
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+	assert.NotContains(t, buf.String(), "empty")
+
+	buf.Reset()
+	sl.Error("error msg", "err", errors.New("boom"))
+	assert.Contains(t, buf.String(), `"level":"error"`)
+	assert.Contains(t, buf.String(), `"err":"boom"`)
+}
+
+func TestNewWithSlog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	log := NewWithSlog(h)
+
+	log.With(zap.String("k", "v"), zap.Namespace("ns"), zap.Int("x", 1)).Info("with ns", zap.Int("y", 2))
+	assert.Contains(t, buf.String(), `"level":"INFO","msg":"with ns","k":"v","ns":{"x":1,"y":2}}`)
+
+	buf.Reset()
+	log.SetLevel(WarnLevel)
+	log.Info("ignored")
+	log.Errorf("error %d", 1)
+	assert.NotContains(t, buf.String(), "ignored")
+	assert.Contains(t, buf.String(), `"level":"ERROR","msg":"error 1"`)
+}
+
+func TestDefault(t *testing.T) {
+	origin := Default()
+	defer SetDefault(origin)
+
+	buf := &bytes.Buffer{}
+	SetDefault(New(&Options{
+		Sinks: []Sink{{Writer: buf}},
+	}))
+	Info("default info", zap.String("k", "v"))
+	Debugf("default debug %d", 1)
+	Warnf("default warn %d", 1)
+	assert.Contains(t, buf.String(), `"msg":"default info","k":"v"`)
+	assert.NotContains(t, buf.String(), "default debug")
+	assert.Contains(t, buf.String(), "default warn 1")
+}
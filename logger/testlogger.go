@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// LoggedEntry 测试 Logger 记录的日志，包括级别、内容和字段
+type LoggedEntry = observer.LoggedEntry
+
+// TestingT testing.T 和 testing.B 的子集，避免引入 testing 包
+type TestingT interface {
+	Helper()
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// TestOptions 测试 Logger 配置
+type TestOptions struct {
+	level Level
+	echo  bool
+}
+
+type TestOption func(*TestOptions)
+
+// WithTestLevel 设置测试 Logger 的日志级别，默认: DebugLevel
+func WithTestLevel(level Level) TestOption {
+	return func(opts *TestOptions) {
+		opts.level = level
+	}
+}
+
+// WithTestEcho 同时通过 t.Log 输出日志
+func WithTestEcho() TestOption {
+	return func(opts *TestOptions) {
+		opts.echo = true
+	}
+}
+
+// TestLogger 在内存中记录日志的 Logger，用于测试代码输出的日志
+type TestLogger struct {
+	Logger
+	t    TestingT
+	logs *observer.ObservedLogs
+}
+
+// NewTest 创建测试 Logger
+func NewTest(t TestingT, opts ...TestOption) *TestLogger {
+	opt := &TestOptions{level: DebugLevel}
+	for _, item := range opts {
+		item(opt)
+	}
+	atomicLevel := zap.NewAtomicLevelAt(opt.level)
+	// 日志级别在 checkLevel 中判断
+	core, logs := observer.New(DebugLevel)
+	if opt.echo {
+		echo := zapcore.NewCore(
+			zapcore.NewConsoleEncoder(newConsoleEncoderConfig()),
+			zapcore.AddSync(testingWriter{t: t}),
+			DebugLevel,
+		)
+		core = zapcore.NewTee(core, echo)
+	}
+	return &TestLogger{
+		Logger: newWithZap(zap.New(core), atomicLevel),
+		t:      t,
+		logs:   logs,
+	}
+}
+
+// Entries 返回所有日志
+func (l *TestLogger) Entries() []LoggedEntry {
+	return l.logs.All()
+}
+
+// Reset 清空已记录的日志
+func (l *TestLogger) Reset() {
+	l.logs.TakeAll()
+}
+
+// FilterByLevel 返回指定级别的日志
+func (l *TestLogger) FilterByLevel(level Level) []LoggedEntry {
+	return l.logs.FilterLevelExact(level).All()
+}
+
+// FilterByMessage 返回内容包含 msgSubstring 的日志
+func (l *TestLogger) FilterByMessage(msgSubstring string) []LoggedEntry {
+	return l.logs.FilterMessageSnippet(msgSubstring).All()
+}
+
+// FilterByField 返回包含指定字段的日志，包括通过 With 添加的字段
+func (l *TestLogger) FilterByField(field zap.Field) []LoggedEntry {
+	return l.logs.FilterField(field).All()
+}
+
+// AssertLogged 断言输出过指定级别且内容包含 msgSubstring 的日志
+func (l *TestLogger) AssertLogged(level Level, msgSubstring string) bool {
+	l.t.Helper()
+	if l.logs.FilterLevelExact(level).FilterMessageSnippet(msgSubstring).Len() > 0 {
+		return true
+	}
+	l.t.Errorf("expected %s log containing %q, got:\n%s", level, msgSubstring, l.dump())
+	return false
+}
+
+// AssertNotLogged 断言没有输出指定级别且内容包含 msgSubstring 的日志
+func (l *TestLogger) AssertNotLogged(level Level, msgSubstring string) bool {
+	l.t.Helper()
+	if l.logs.FilterLevelExact(level).FilterMessageSnippet(msgSubstring).Len() == 0 {
+		return true
+	}
+	l.t.Errorf("unexpected %s log containing %q, got:\n%s", level, msgSubstring, l.dump())
+	return false
+}
+
+func (l *TestLogger) dump() string {
+	sb := &strings.Builder{}
+	for _, e := range l.logs.All() {
+		sb.WriteString("\t")
+		sb.WriteString(e.Level.String())
+		sb.WriteString(" ")
+		sb.WriteString(e.Message)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// testingWriter 通过 t.Logf 输出日志
+type testingWriter struct {
+	t TestingT
+}
+
+func (w testingWriter) Write(p []byte) (int, error) {
+	w.t.Logf("%s", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
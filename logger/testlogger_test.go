@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeT 记录断言失败信息
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Logf(format string, args ...interface{}) {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestTestLogger(t *testing.T) {
+	log := NewTest(t, WithTestEcho())
+	log.With(zap.String("order_id", "o-1")).Info("order created", zap.Int64("uid", 1000))
+	log.Warnf("stock low: %d", 3)
+	log.Debug("debug msg")
+
+	assert.Len(t, log.Entries(), 3)
+	log.AssertLogged(InfoLevel, "order created")
+	log.AssertLogged(WarnLevel, "stock low")
+	log.AssertNotLogged(ErrorLevel, "order")
+
+	entries := log.FilterByField(zap.String("order_id", "o-1"))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, int64(1000), entries[0].ContextMap()["uid"])
+	assert.Len(t, log.FilterByLevel(DebugLevel), 1)
+	assert.Len(t, log.FilterByMessage("stock"), 1)
+
+	log.Reset()
+	assert.Empty(t, log.Entries())
+}
+
+func TestTestLoggerAssertFailed(t *testing.T) {
+	ft := &fakeT{}
+	log := NewTest(ft, WithTestLevel(InfoLevel))
+	log.Debug("debug msg")
+	assert.False(t, log.AssertLogged(DebugLevel, "debug msg"))
+	assert.Len(t, ft.errors, 1)
+
+	log.Error("error msg")
+	assert.False(t, log.AssertNotLogged(ErrorLevel, "error"))
+	assert.Len(t, ft.errors, 2)
+}
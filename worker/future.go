@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+)
+
+// PanicError 任务执行 panic 时返回的错误
+type PanicError struct {
+	Value any    // recover 得到的值
+	Stack []byte // panic 时的调用栈
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("worker task panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Future 异步任务的执行结果
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Done 任务执行完成后关闭
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err 任务返回的错误，任务未完成时返回 nil
+func (f *Future[T]) Err() error {
+	select {
+	case <-f.done:
+		return f.err
+	default:
+		return nil
+	}
+}
+
+// Get 等待任务执行完成并返回结果，ctx 取消时返回 ctx.Err()
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// SubmitFunc 提交一个有返回值的任务，任务 panic 时 Future 返回 *PanicError，被拒绝策略丢弃时返回 ErrTaskDiscarded
+func SubmitFunc[T any](p *Pool, fn func() (T, error)) (*Future[T], error) {
+	f := &Future[T]{done: make(chan struct{})}
+	err := p.submit(context.Background(), taskItem{
+		fn: func() {
+			f.val, f.err = fn()
+			close(f.done)
+		},
+		onPanic: func(recovered any, stack []byte) {
+			f.err = &PanicError{Value: recovered, Stack: stack}
+			close(f.done)
+		},
+		onDiscard: func() {
+			f.err = ErrTaskDiscarded
+			close(f.done)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// WaitAll 等待所有任务完成并按顺序返回结果，任意任务失败时立即返回该错误
+func WaitAll[T any](ctx context.Context, futures ...*Future[T]) ([]T, error) {
+	done, stop := notifyDone(futures)
+	defer close(stop)
+	for range futures {
+		select {
+		case i := <-done:
+			if err := futures[i].err; err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	vals := make([]T, len(futures))
+	for i, f := range futures {
+		vals[i] = f.val
+	}
+	return vals, nil
+}
+
+// WaitAny 等待任意一个任务完成，返回它的下标和结果
+func WaitAny[T any](ctx context.Context, futures ...*Future[T]) (int, T, error) {
+	var zero T
+	if len(futures) == 0 {
+		return -1, zero, nil
+	}
+	done, stop := notifyDone(futures)
+	defer close(stop)
+	select {
+	case i := <-done:
+		f := futures[i]
+		return i, f.val, f.err
+	case <-ctx.Done():
+		return -1, zero, ctx.Err()
+	}
+}
+
+// notifyDone 任务完成时把下标发送到 done，关闭 stop 后停止等待
+func notifyDone[T any](futures []*Future[T]) (done chan int, stop chan struct{}) {
+	done = make(chan int, len(futures))
+	stop = make(chan struct{})
+	for i, f := range futures {
+		go func(i int, f *Future[T]) {
+			select {
+			case <-f.done:
+				done <- i
+			case <-stop:
+			}
+		}(i, f)
+	}
+	return done, stop
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmitFunc(t *testing.T) {
+	worker := New("future-worker", WithCapacity(3))
+	defer worker.Release()
+
+	f, err := SubmitFunc(worker, func() (int, error) {
+		time.Sleep(time.Millisecond * 10)
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Err() != nil {
+		t.Fatal("err before done")
+	}
+	val, err := f.Get(context.Background())
+	if err != nil || val != 1 {
+		t.Fatalf("got %d, %v", val, err)
+	}
+
+	f, _ = SubmitFunc(worker, func() (int, error) {
+		panic("boom")
+	})
+	<-f.Done()
+	var pe *PanicError
+	if !errors.As(f.Err(), &pe) || pe.Value != "boom" {
+		t.Fatalf("err %v, want panic error", f.Err())
+	}
+	if !strings.Contains(string(pe.Stack), "future_test.go") {
+		t.Fatalf("stack does not contain panic source: %s", pe.Stack)
+	}
+
+	f, _ = SubmitFunc(worker, func() (int, error) {
+		time.Sleep(time.Second)
+		return 0, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err = f.Get(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("err %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitAllAny(t *testing.T) {
+	worker := New("wait-worker", WithCapacity(5))
+	defer worker.Release()
+	ctx := context.Background()
+
+	var futures []*Future[int]
+	for i := 0; i < 3; i++ {
+		n := i
+		f, _ := SubmitFunc(worker, func() (int, error) {
+			time.Sleep(time.Millisecond * time.Duration(30-n*10))
+			return n, nil
+		})
+		futures = append(futures, f)
+	}
+	idx, val, err := WaitAny(ctx, futures...)
+	if err != nil || idx != 2 || val != 2 {
+		t.Fatalf("WaitAny got %d, %d, %v", idx, val, err)
+	}
+	vals, err := WaitAll(ctx, futures...)
+	if err != nil || len(vals) != 3 || vals[0] != 0 || vals[2] != 2 {
+		t.Fatalf("WaitAll got %v, %v", vals, err)
+	}
+
+	errFail := errors.New("fail")
+	slow, _ := SubmitFunc(worker, func() (int, error) {
+		time.Sleep(time.Second)
+		return 0, nil
+	})
+	failed, _ := SubmitFunc(worker, func() (int, error) {
+		return 0, errFail
+	})
+	start := time.Now()
+	if _, err = WaitAll(ctx, slow, failed); err != errFail {
+		t.Fatalf("err %v, want %v", err, errFail)
+	}
+	if time.Since(start) > time.Millisecond*500 {
+		t.Fatal("WaitAll does not return on first error")
+	}
+}
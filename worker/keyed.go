@@ -0,0 +1,238 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"sync"
+
+	"github.com/fengjx/go-halo/halo"
+)
+
+var ErrLaneFull = errors.New("worker keyed lane is full")
+
+const defaultLaneSize = 100
+
+// KeyedExecutor 按 key 串行执行任务，相同 key 的任务严格按提交顺序执行，不同 key 的任务在 Pool 中并行执行。
+// key 通过 hash 分配到固定数量的 lane，每个 lane 同一时刻最多占用 Pool 的一个协程
+type KeyedExecutor struct {
+	pool     *Pool
+	seed     maphash.Seed
+	lanes    []*lane
+	laneSize int
+	wg       sync.WaitGroup // 正在执行的 lane
+	quit     chan struct{}
+	once     sync.Once
+}
+
+// lane 固定长度的任务队列，有任务时占用 Pool 的一个协程按顺序执行
+type lane struct {
+	mu      sync.Mutex
+	tasks   []Task
+	head    int
+	size    int
+	running bool          // 是否已经提交到 Pool 执行
+	waiting int           // 等待 lane 空闲的提交数量
+	space   chan struct{} // lane 有空位时关闭，通知等待的提交
+}
+
+// NewKeyedExecutor 创建按 key 串行执行的执行器，lanes 为 lane 数量，默认与 Pool 容量相同，laneSize 为每个 lane 最多排队的任务数量，默认 100
+func NewKeyedExecutor(p *Pool, lanes, laneSize int) *KeyedExecutor {
+	if lanes <= 0 {
+		lanes = p.Cap()
+	}
+	if laneSize <= 0 {
+		laneSize = defaultLaneSize
+	}
+	e := &KeyedExecutor{
+		pool:     p,
+		seed:     maphash.MakeSeed(),
+		lanes:    make([]*lane, lanes),
+		laneSize: laneSize,
+		quit:     make(chan struct{}),
+	}
+	for i := range e.lanes {
+		e.lanes[i] = &lane{
+			tasks: make([]Task, laneSize),
+			space: make(chan struct{}),
+		}
+	}
+	return e
+}
+
+func (e *KeyedExecutor) lane(key string) *lane {
+	return e.lanes[maphash.String(e.seed, key)%uint64(len(e.lanes))]
+}
+
+// SubmitKeyed 提交任务，lane 满时最多等待 Pool 的 submitTimeout，超时返回 ErrLaneFull
+func (e *KeyedExecutor) SubmitKeyed(key string, t Task) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.pool.submitTimeout)
+	defer cancel()
+	err := e.SubmitKeyedCtx(ctx, key, t)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrLaneFull
+	}
+	return err
+}
+
+// SubmitKeyedCtx 提交任务，lane 满时一直等待直到 ctx 取消。
+// 提交到 Pool 失败时返回错误并只撤回当前任务；lane 的执行任务被 Pool 的拒绝策略丢弃时，
+// lane 中还没有执行的任务会一起丢弃，计入 Stats 的 Rejected
+func (e *KeyedExecutor) SubmitKeyedCtx(ctx context.Context, key string, t Task) error {
+	l := e.lane(key)
+	l.mu.Lock()
+	for l.size == len(l.tasks) {
+		if e.released() {
+			l.mu.Unlock()
+			return ErrWorkerReleased
+		}
+		l.waiting++
+		space := l.space
+		l.mu.Unlock()
+		var err error
+		select {
+		case <-space:
+		case <-e.quit:
+			err = ErrWorkerReleased
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		l.mu.Lock()
+		l.waiting--
+		if err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	if e.released() {
+		l.mu.Unlock()
+		return ErrWorkerReleased
+	}
+	l.tasks[(l.head+l.size)%len(l.tasks)] = t
+	l.size++
+	if l.running {
+		l.mu.Unlock()
+		return nil
+	}
+	// lane 之前是空的，占用 Pool 的一个协程开始执行。
+	// 提交时不持有锁，RejectCallerRuns 在当前协程执行 run 时不会死锁
+	l.running = true
+	e.wg.Add(1)
+	l.mu.Unlock()
+	err := e.start(ctx, l)
+	if err == nil {
+		return nil
+	}
+	// 只撤回当前任务，lane 为空时当前任务一定在队首。
+	// 提交期间进入 lane 的任务已经返回成功，在单独的协程中重新提交执行，不再阻塞当前提交
+	l.mu.Lock()
+	l.tasks[l.head] = nil
+	l.head = (l.head + 1) % len(l.tasks)
+	l.size--
+	l.notifySpaceLocked()
+	rest := l.size > 0
+	if !rest {
+		l.running = false
+	}
+	l.mu.Unlock()
+	if !rest {
+		e.wg.Done()
+		return err
+	}
+	go func() {
+		if e.start(context.Background(), l) != nil {
+			e.drop(l, 0)
+		}
+	}()
+	return err
+}
+
+// start 提交 lane 的执行任务到 Pool，调用前需要设置 running
+func (e *KeyedExecutor) start(ctx context.Context, l *lane) error {
+	return e.pool.submit(ctx, taskItem{
+		fn: func() { e.run(l) },
+		// Pool 已经把执行任务计入 Rejected
+		onDiscard: func() { e.drop(l, 1) },
+	})
+}
+
+// drop 丢弃 lane 中还没有执行的任务并释放 lane，丢弃的任务计入 Rejected，counted 为已经计入的数量
+func (e *KeyedExecutor) drop(l *lane, counted int) {
+	l.mu.Lock()
+	if n := l.size - counted; n > 0 {
+		e.pool.stats.rejected.Add(uint64(n))
+	}
+	for i := range l.tasks {
+		l.tasks[i] = nil
+	}
+	l.head = 0
+	l.size = 0
+	l.running = false
+	l.notifySpaceLocked()
+	l.mu.Unlock()
+	e.wg.Done()
+}
+
+// notifySpaceLocked lane 有空位时唤醒等待的提交，需要持有 mu
+func (l *lane) notifySpaceLocked() {
+	if l.waiting > 0 {
+		close(l.space)
+		l.space = make(chan struct{})
+	}
+}
+
+// run 按顺序执行 lane 中的任务，直到 lane 为空
+func (e *KeyedExecutor) run(l *lane) {
+	defer e.wg.Done()
+	for {
+		l.mu.Lock()
+		if l.size == 0 {
+			l.running = false
+			l.mu.Unlock()
+			return
+		}
+		t := l.tasks[l.head]
+		l.tasks[l.head] = nil
+		l.head = (l.head + 1) % len(l.tasks)
+		l.size--
+		l.notifySpaceLocked()
+		l.mu.Unlock()
+		e.doTask(t)
+	}
+}
+
+func (e *KeyedExecutor) doTask(t Task) {
+	defer func() {
+		if err := recover(); err != nil {
+			stack := halo.Stack(3)
+			e.pool.stats.panicked.Add(1)
+			e.pool.handlePanic(t, err, stack, true)
+		}
+	}()
+	t()
+}
+
+// Pending key 所在 lane 中等待执行的任务数量，可以用来判断是否需要限流
+func (e *KeyedExecutor) Pending(key string) int {
+	l := e.lane(key)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+func (e *KeyedExecutor) released() bool {
+	select {
+	case <-e.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release 停止接收新任务，等待 lane 中已经提交的任务执行完成，不会停止 Pool
+func (e *KeyedExecutor) Release() {
+	e.once.Do(func() {
+		close(e.quit)
+	})
+	e.wg.Wait()
+}
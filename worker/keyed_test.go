@@ -0,0 +1,208 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedExecutor(t *testing.T) {
+	worker := New("keyed-worker", WithCapacity(4))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 4, 1000)
+
+	var (
+		mu     sync.Mutex
+		result = make(map[string][]int)
+	)
+	for i := 0; i < 100; i++ {
+		for k := 0; k < 5; k++ {
+			key, n := fmt.Sprintf("user-%d", k), i
+			err := e.SubmitKeyed(key, func() {
+				if n%10 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				if n == 50 {
+					panic("boom")
+				}
+				mu.Lock()
+				result[key] = append(result[key], n)
+				mu.Unlock()
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	e.Release()
+	if err := e.SubmitKeyed("user-0", func() {}); err != ErrWorkerReleased {
+		t.Fatalf("err %v, want %v", err, ErrWorkerReleased)
+	}
+	if len(result) != 5 {
+		t.Fatalf("keys %d, want 5", len(result))
+	}
+	for key, nums := range result {
+		if len(nums) != 99 {
+			t.Fatalf("%s ran %d tasks, want 99", key, len(nums))
+		}
+		for i := 1; i < len(nums); i++ {
+			if nums[i] <= nums[i-1] {
+				t.Fatalf("%s out of order: %v", key, nums)
+			}
+		}
+	}
+}
+
+func TestKeyedBackpressure(t *testing.T) {
+	worker := New("keyed-full-worker", WithCapacity(2), WithSubmitTimeout(time.Millisecond*20))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 1, 2)
+	block := make(chan struct{})
+	_ = e.SubmitKeyed("a", func() { <-block })
+	// 第一个任务已经开始执行，lane 还可以排队 2 个
+	time.Sleep(time.Millisecond * 10)
+	for i := 0; i < 2; i++ {
+		if err := e.SubmitKeyed("a", func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := e.Pending("a"); n != 2 {
+		t.Fatalf("pending %d, want 2", n)
+	}
+	if err := e.SubmitKeyed("b", func() {}); err != ErrLaneFull {
+		t.Fatalf("err %v, want %v", err, ErrLaneFull)
+	}
+	time.AfterFunc(time.Millisecond*5, func() { close(block) })
+	if err := e.SubmitKeyed("a", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	e.Release()
+	if n := e.Pending("a"); n != 0 {
+		t.Fatalf("pending %d after release, want 0", n)
+	}
+}
+
+func TestKeyedCallerRuns(t *testing.T) {
+	worker := New("keyed-caller-runs-worker", WithCapacity(1), WithQueue(1, RejectCallerRuns))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 4, 10)
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	_ = worker.Submit(func() {})
+
+	// Pool 已满，lane 在提交的协程中执行，不会因为持有 lane 的锁而死锁
+	ran := make(chan struct{})
+	go func() {
+		_ = e.SubmitKeyed("a", func() {})
+		_ = e.SubmitKeyed("a", func() {})
+		close(ran)
+	}()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("caller runs deadlock")
+	}
+	close(block)
+	e.Release()
+}
+
+func TestKeyedDiscard(t *testing.T) {
+	worker := New("keyed-discard-worker", WithCapacity(1), WithQueue(1, RejectDiscard))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 1, 10)
+	block := make(chan struct{})
+	queued := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	_ = worker.Submit(func() { close(queued) })
+
+	// lane 的执行任务被丢弃后 lane 恢复空闲，之后的任务可以正常执行
+	if err := e.SubmitKeyed("a", func() { t.Error("discarded task ran") }); err != nil {
+		t.Fatal(err)
+	}
+	if n := e.Pending("a"); n != 0 {
+		t.Fatalf("pending %d after discard, want 0", n)
+	}
+	if n := worker.Stats().Rejected; n != 1 {
+		t.Fatalf("rejected %d, want 1", n)
+	}
+	close(block)
+	<-queued
+	done := make(chan struct{})
+	if err := e.SubmitKeyed("a", func() { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lane not recovered after discard")
+	}
+
+	released := make(chan struct{})
+	go func() {
+		e.Release()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("release hang after discard")
+	}
+}
+
+func TestKeyedDiscardOldest(t *testing.T) {
+	worker := New("keyed-discard-oldest-worker", WithCapacity(1), WithQueue(1, RejectDiscardOldest))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 1, 10)
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+
+	// lane 的执行任务在队列中，之后进入 lane 的任务随执行任务一起丢弃，全部计入 Rejected
+	for i := 0; i < 3; i++ {
+		if err := e.SubmitKeyed("a", func() { t.Error("discarded task ran") }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = worker.Submit(func() {})
+	if n := e.Pending("a"); n != 0 {
+		t.Fatalf("pending %d after discard, want 0", n)
+	}
+	if n := worker.Stats().Rejected; n != 3 {
+		t.Fatalf("rejected %d, want 3", n)
+	}
+	close(block)
+	e.Release()
+}
+
+func TestKeyedSubmitFailed(t *testing.T) {
+	worker := New("keyed-failed-worker", WithCapacity(1), WithSubmitTimeout(time.Millisecond*100))
+	defer worker.Release()
+	e := NewKeyedExecutor(worker, 1, 10)
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+
+	// 第一个提交等待协程时，后面的提交进入 lane 并返回成功
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*30)
+	defer cancel()
+	failed := make(chan error, 1)
+	go func() {
+		failed <- e.SubmitKeyedCtx(ctx, "a", func() { t.Error("failed task ran") })
+	}()
+	time.Sleep(time.Millisecond * 10)
+	done := make(chan struct{})
+	if err := e.SubmitKeyed("a", func() { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	// 第一个提交失败后只撤回自己的任务，剩下的任务重新提交执行
+	if err := <-failed; err != context.DeadlineExceeded {
+		t.Fatalf("err %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("accepted task lost after submit failed")
+	}
+	e.Release()
+}
@@ -15,3 +15,60 @@ func WithSubmitTimeout(timeout time.Duration) Option {
 		p.submitTimeout = timeout
 	}
 }
+
+// WithIdleTimeout 空闲协程超过 timeout 没有任务会被回收
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.idleTimeout = timeout
+	}
+}
+
+// WithTaskTimeout CtxTask 默认执行超时时间，超时后任务的 context 会被取消
+func WithTaskTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.taskTimeout = timeout
+	}
+}
+
+// WithMetricsObserver 设置指标观察者
+func WithMetricsObserver(observer MetricsObserver) Option {
+	return func(p *Pool) {
+		p.observer = observer
+	}
+}
+
+// WithQueue 没有空闲协程时任务先进入长度为 size 的队列，不再等待 submitTimeout，队列满时按 policy 处理
+func WithQueue(size int, policy RejectPolicy) Option {
+	return func(p *Pool) {
+		p.queueSize = size
+		p.rejectPolicy = policy
+	}
+}
+
+// WithAging 队列中的任务每等待 aging 时间优先级提升一级，默认 1s
+func WithAging(aging time.Duration) Option {
+	return func(p *Pool) {
+		p.aging = aging
+	}
+}
+
+// WithLogger 设置日志输出，默认输出到 stderr，实现 LevelLogger 时按级别输出，可以使用 logger.NewPrintfLogger 输出到 logger.Logger
+func WithLogger(log Logger) Option {
+	return func(p *Pool) {
+		p.log = log
+	}
+}
+
+// WithPanicHandler 任务 panic 时回调，设置后不再输出 panic 日志，t 为提交的任务，stack 为 panic 时的调用栈
+func WithPanicHandler(handler func(t Task, recovered any, stack []byte)) Option {
+	return func(p *Pool) {
+		p.panicHandler = handler
+	}
+}
+
+// WithSubmitTimeoutLog 是否输出提交超时日志，默认输出，提交超时可以通过 Submit 返回的错误和 Stats 获取
+func WithSubmitTimeoutLog(enable bool) Option {
+	return func(p *Pool) {
+		p.timeoutLog = enable
+	}
+}
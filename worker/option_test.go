@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogger 记录输出的日志
+type testLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) logged(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.logs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// levelTestLogger 记录日志时带上级别
+type levelTestLogger struct {
+	testLogger
+}
+
+func (l *levelTestLogger) Warnf(format string, args ...interface{}) {
+	l.Printf("[warn] "+format, args...)
+}
+
+func (l *levelTestLogger) Errorf(format string, args ...interface{}) {
+	l.Printf("[error] "+format, args...)
+}
+
+func TestWithLogger(t *testing.T) {
+	log := &levelTestLogger{}
+	worker := New("logger-worker", WithCapacity(1), WithSubmitTimeout(time.Millisecond*10), WithLogger(log))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	_ = worker.Submit(func() {})
+	close(block)
+	_ = worker.Submit(func() { panic("boom") })
+	worker.Release()
+
+	for _, msg := range []string{"[warn] submit worker task timeout", "[error] recover panic[boom]", "release"} {
+		if !log.logged(msg) {
+			t.Fatalf("%q not logged", msg)
+		}
+	}
+}
+
+func TestWithPanicHandler(t *testing.T) {
+	log := &testLogger{}
+	type panicked struct {
+		recovered any
+		stack     []byte
+	}
+	ch := make(chan panicked, 2)
+	worker := New("panic-worker", WithCapacity(1), WithSubmitTimeout(time.Millisecond*10),
+		WithLogger(log), WithSubmitTimeoutLog(false),
+		WithPanicHandler(func(task Task, recovered any, stack []byte) {
+			ch <- panicked{recovered: recovered, stack: stack}
+		}))
+	_ = worker.Submit(func() { panic("boom") })
+	p := <-ch
+	if p.recovered != "boom" || !strings.Contains(string(p.stack), "option_test.go") {
+		t.Fatalf("unexpected panic %v %s", p.recovered, p.stack)
+	}
+
+	// Future 的 panic 也会回调
+	f, _ := SubmitFunc(worker, func() (int, error) { panic("future") })
+	if _, err := f.Get(context.Background()); err == nil {
+		t.Fatal("want panic error")
+	}
+	if p = <-ch; p.recovered != "future" {
+		t.Fatalf("unexpected panic %v", p.recovered)
+	}
+
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	if err := worker.Submit(func() {}); err != ErrSubmitTimeout {
+		t.Fatalf("err %v, want %v", err, ErrSubmitTimeout)
+	}
+	close(block)
+	worker.Release()
+	for _, msg := range []string{"recover panic", "submit worker task timeout"} {
+		if log.logged(msg) {
+			t.Fatalf("%q logged", msg)
+		}
+	}
+}
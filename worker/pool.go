@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -14,53 +15,129 @@ import (
 var (
 	ErrSubmitTimeout  = errors.New("exceeded maximum capacity abd submit timeout")
 	ErrWorkerReleased = errors.New("worker has released")
+	ErrQueueFull      = errors.New("worker task queue is full")
+	ErrTaskDiscarded  = errors.New("worker task discarded by reject policy")
 )
 
 type Pool struct {
 	name          string         // worker 名称，用来输出日志，便于排查问题
 	capacity      int            // 最大协程数量
 	submitTimeout time.Duration  // 任务提交超时时间，避免长时间阻塞，占用资源导致宕机
-	active        chan struct{}  // 用来计数，控制并发
-	tasks         chan Task      // 用来提交任务
+	idleTimeout   time.Duration  // 空闲协程超过这个时间会被回收
+	taskTimeout   time.Duration  // CtxTask 默认执行超时时间，0 表示不限制
+	lock          sync.Mutex     // 保护 running、idle、waiting、queue
+	running       int            // 当前协程数量，协程确定退出时立即减少
+	idle          []*goWorker    // 空闲协程，按最后使用时间从早到晚排列
+	waiting       int            // 等待空闲协程的提交数量
+	free          chan struct{}  // 有协程空闲时通知等待的提交
+	queueSize     int            // 任务队列长度，0 表示不使用队列
+	queue         *taskQueue     // 没有空闲协程时暂存任务
+	rejectPolicy  RejectPolicy   // 队列满时的拒绝策略
+	aging         time.Duration  // 队列中的任务每等待 aging 时间优先级提升一级
 	wg            sync.WaitGroup // 保证任务优雅停止
 	quit          chan struct{}  // worker 停止信号
+	releaseOnce   sync.Once
+	done          chan struct{} // 所有协程退出后关闭
+	ctx           context.Context
+	cancel        context.CancelFunc // ReleaseCtx 超时或 ReleaseNow 时取消所有 CtxTask 的 context
 	log           Logger
+	timeoutLog    bool                                      // 是否输出提交超时日志
+	panicHandler  func(t Task, recovered any, stack []byte) // 任务 panic 时回调，为空时输出日志
+	sched         *scheduler                                // 定时任务调度器，第一次使用时创建
+	stats         *poolStats
+	observer      MetricsObserver
 }
 
 type Task func()
 
+// CtxTask 可以感知取消的任务，ctx 在 pool 停止或任务超时后取消
+type CtxTask func(ctx context.Context)
+
 // Logger is used for logging formatted messages.
 type Logger interface {
 	// Printf must have the same semantics as log.Printf.
 	Printf(format string, args ...interface{})
 }
 
+// LevelLogger 可以按级别输出的 Logger，WithLogger 设置的 Logger 实现了这个接口时，
+// 任务 panic 使用 Errorf 输出，提交超时、停止超时和定时任务提交失败使用 Warnf 输出，其他日志使用 Printf 输出
+type LevelLogger interface {
+	Logger
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
 const (
 	defaultCapacity      = 100
 	maxCapacity          = 10000
 	defaultSubmitTimeout = time.Millisecond * 500
+	defaultIdleTimeout   = time.Second * 10
+	defaultAging         = time.Second
 )
 
+// taskItem 提交给协程执行的任务
+type taskItem struct {
+	fn        Task
+	submitted time.Time                         // 提交时间，用来统计等待时间
+	onPanic   func(recovered any, stack []byte) // 任务 panic 时回调，用来把 panic 转换为 Future 的错误
+	onDiscard func()                            // 任务被拒绝策略丢弃时回调
+	priority  Priority                          // 任务优先级，队列中优先级高的先执行
+	seq       uint64                            // 入队顺序
+}
+
+// goWorker 常驻协程，执行完任务后放回空闲列表复用
+type goWorker struct {
+	pool     *Pool
+	task     chan taskItem
+	lastUsed time.Time
+}
+
+func (w *goWorker) run() {
+	p := w.pool
+	defer p.wg.Done()
+	for t := range w.task {
+		// 空任务表示协程已从空闲列表移除并减少了 running，直接退出
+		if t.fn == nil {
+			return
+		}
+		for {
+			p.doTask(t)
+			next, ok := p.nextTask(w)
+			if !ok {
+				return
+			}
+			if next.fn == nil {
+				// 已放回空闲列表，等待下一个任务
+				break
+			}
+			t = next
+		}
+	}
+}
+
 func New(name string, opts ...Option) *Pool {
 	p := &Pool{
 		name:  name,
-		tasks: make(chan Task),
+		free:  make(chan struct{}, 1),
 		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+		stats: newPoolStats(),
+
+		timeoutLog: true,
 	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
 
 	for _, opt := range opts {
 		opt(p)
 	}
 
-	capacity := p.capacity
-	if capacity <= 0 {
-		capacity = defaultCapacity
+	p.capacity = normalizeCapacity(p.capacity)
+	if p.aging <= 0 {
+		p.aging = defaultAging
 	}
-	if capacity > maxCapacity {
-		capacity = maxCapacity
+	if p.queueSize > 0 {
+		p.queue = newTaskQueue(p.queueSize, p.aging)
 	}
-	p.capacity = capacity
-	p.active = make(chan struct{}, capacity)
 
 	timeout := p.submitTimeout
 	if timeout == 0 {
@@ -68,58 +145,437 @@ func New(name string, opts ...Option) *Pool {
 	}
 	p.submitTimeout = timeout
 
+	if p.idleTimeout <= 0 {
+		p.idleTimeout = defaultIdleTimeout
+	}
+
 	if p.log == nil {
 		p.log = Logger(log.New(os.Stderr, fmt.Sprintf("[worker-%s]: ", p.name), log.LstdFlags|log.Lmsgprefix|log.Lmicroseconds))
 	}
 
-	go p.run()
+	go p.purge()
 	return p
 }
 
-func (p *Pool) doTask(t Task) {
-	p.wg.Add(1)
-	go func() {
-		defer func() {
-			if err := recover(); err != nil {
-				stack := halo.Stack(10)
-				p.log.Printf("recover panic[%s] and exit - %s\n", err, stack)
+func normalizeCapacity(capacity int) int {
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	if capacity > maxCapacity {
+		capacity = maxCapacity
+	}
+	return capacity
+}
+
+func (p *Pool) doTask(t taskItem) {
+	start := time.Now()
+	wait := start.Sub(t.submitted)
+	p.stats.queueWait.observe(wait)
+	ps := p.stats.priority(t.priority)
+	ps.queueWait.observe(wait)
+	p.stats.running.Add(1)
+	if p.observer != nil {
+		p.observer.OnStart(p.name, wait)
+	}
+	panicked := true
+	defer func() {
+		if panicked {
+			err := recover()
+			stack := halo.Stack(3)
+			p.stats.panicked.Add(1)
+			if t.onPanic != nil {
+				t.onPanic(err, stack)
 			}
-			p.wg.Done()
-			<-p.active
-		}()
-		t()
+			p.handlePanic(t.fn, err, stack, t.onPanic == nil)
+		}
+		latency := time.Since(start)
+		p.stats.running.Add(-1)
+		p.stats.completed.Add(1)
+		ps.completed.Add(1)
+		p.stats.latency.observe(latency)
+		if p.observer != nil {
+			p.observer.OnDone(p.name, latency, panicked)
+		}
 	}()
+	t.fn()
+	panicked = false
 }
 
-func (p *Pool) run() {
+// handlePanic 调用 panicHandler，没有设置时按 logging 决定是否输出日志
+func (p *Pool) handlePanic(t Task, recovered any, stack []byte, logging bool) {
+	if p.panicHandler != nil {
+		p.panicHandler(t, recovered, stack)
+		return
+	}
+	if logging {
+		p.errorf("recover panic[%v] and exit - %s\n", recovered, stack)
+	}
+}
+
+func (p *Pool) warnf(format string, args ...interface{}) {
+	if l, ok := p.log.(LevelLogger); ok {
+		l.Warnf(format, args...)
+		return
+	}
+	p.log.Printf(format, args...)
+}
+
+func (p *Pool) errorf(format string, args ...interface{}) {
+	if l, ok := p.log.(LevelLogger); ok {
+		l.Errorf(format, args...)
+		return
+	}
+	p.log.Printf(format, args...)
+}
+
+// released 是否已经停止，需要持有 lock
+func (p *Pool) released() bool {
+	select {
+	case <-p.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// notify 唤醒一个等待的提交，被唤醒的提交拿到协程后如果还有空闲会继续唤醒下一个，需要持有 lock
+func (p *Pool) notify() {
+	if p.waiting == 0 {
+		return
+	}
+	select {
+	case p.free <- struct{}{}:
+	default:
+	}
+}
+
+// available 是否有可用的协程，需要持有 lock
+func (p *Pool) available() bool {
+	return len(p.idle) > 0 || p.running < p.capacity
+}
+
+var timerPool sync.Pool
+
+func acquireTimer(d time.Duration) *time.Timer {
+	if v := timerPool.Get(); v != nil {
+		t := v.(*time.Timer)
+		t.Reset(d)
+		return t
+	}
+	return time.NewTimer(d)
+}
+
+func releaseTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	timerPool.Put(t)
+}
+
+// acquireWorker 取出一个空闲协程，没有空闲协程且未达到容量上限时创建新的协程，都不满足时返回 nil，需要持有 lock
+func (p *Pool) acquireWorker() *goWorker {
+	var w *goWorker
+	if n := len(p.idle); n > 0 {
+		w = p.idle[n-1]
+		p.idle[n-1] = nil
+		p.idle = p.idle[:n-1]
+	} else if p.running < p.capacity {
+		p.running++
+		p.wg.Add(1)
+		w = &goWorker{pool: p, task: make(chan taskItem, 1)}
+		go w.run()
+	} else {
+		return nil
+	}
+	if p.available() {
+		p.notify()
+	}
+	return w
+}
+
+// getWorker 获取一个空闲协程，没有空闲协程且未达到容量上限时创建新的协程
+// ctx 没有设置 deadline 时最多等待 submitTimeout
+func (p *Pool) getWorker(ctx context.Context) (*goWorker, error) {
+	var (
+		timer   *time.Timer
+		timeout <-chan time.Time
+	)
+	defer func() {
+		if timer != nil {
+			releaseTimer(timer)
+		}
+	}()
+	for {
+		p.lock.Lock()
+		if p.released() {
+			p.lock.Unlock()
+			return nil, ErrWorkerReleased
+		}
+		if w := p.acquireWorker(); w != nil {
+			p.lock.Unlock()
+			return w, nil
+		}
+		p.waiting++
+		p.lock.Unlock()
+
+		if _, ok := ctx.Deadline(); !ok && timer == nil {
+			timer = acquireTimer(p.submitTimeout)
+			timeout = timer.C
+		}
+		var err error
+		select {
+		case <-p.free:
+		case <-p.quit:
+			err = ErrWorkerReleased
+		case <-timeout:
+			err = ErrSubmitTimeout
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		p.lock.Lock()
+		p.waiting--
+		p.lock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextTask 协程执行完任务后优先从队列取下一个任务，队列为空时放回空闲列表并返回空任务，返回 false 表示协程需要退出。
+// 退出时在同一个锁内减少 running，避免缩容时多个协程同时看到 running 超过容量而全部退出
+func (p *Pool) nextTask(w *goWorker) (taskItem, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.running > p.capacity {
+		p.running--
+		p.notify()
+		return taskItem{}, false
+	}
+	if p.queue != nil && p.queue.len() > 0 {
+		return p.queue.pop(), true
+	}
+	if p.released() {
+		p.running--
+		return taskItem{}, false
+	}
+	w.lastUsed = time.Now()
+	p.idle = append(p.idle, w)
+	p.notify()
+	return taskItem{}, true
+}
+
+// purge 定期回收空闲超时的协程
+func (p *Pool) purge() {
+	ticker := time.NewTicker(p.idleTimeout)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-p.quit:
-			p.log.Printf("exit")
-			<-p.active
 			return
-		case t := <-p.tasks:
-			p.doTask(t)
+		case now := <-ticker.C:
+			expiry := now.Add(-p.idleTimeout)
+			p.lock.Lock()
+			n := 0
+			for n < len(p.idle) && p.idle[n].lastUsed.Before(expiry) {
+				n++
+			}
+			expired := make([]*goWorker, n)
+			copy(expired, p.idle[:n])
+			p.idle = append(p.idle[:0], p.idle[n:]...)
+			p.running -= n
+			p.notify()
+			p.lock.Unlock()
+			for _, w := range expired {
+				w.task <- taskItem{}
+			}
 		}
 	}
 }
 
 func (p *Pool) Submit(t Task) error {
-	select {
-	case <-p.quit:
-		return ErrWorkerReleased
-	case p.active <- struct{}{}:
-		p.tasks <- t
-		return nil
-	case <-time.After(p.submitTimeout):
-		p.log.Printf("submit worker task timeout")
-		return ErrSubmitTimeout
+	return p.submit(context.Background(), taskItem{fn: t})
+}
+
+// SubmitCtx 提交可以感知取消的任务，ctx 取消时停止等待，ctx 没有设置 deadline 时最多等待 submitTimeout。
+// 任务收到的 context 保留 ctx 中的值，但不受 ctx 取消影响，在 pool 停止或超过 WithTaskTimeout 设置的时间后取消
+func (p *Pool) SubmitCtx(ctx context.Context, t CtxTask) error {
+	return p.SubmitCtxTimeout(ctx, p.taskTimeout, t)
+}
+
+// SubmitCtxTimeout 与 SubmitCtx 相同，单独指定任务执行超时时间，0 表示不限制
+func (p *Pool) SubmitCtxTimeout(ctx context.Context, timeout time.Duration, t CtxTask) error {
+	return p.submit(ctx, taskItem{fn: func() {
+		taskCtx, cancel := p.taskContext(ctx, timeout)
+		defer cancel()
+		t(taskCtx)
+		if errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+			p.stats.taskTimedOut.Add(1)
+		}
+	}})
+}
+
+// taskContext 创建任务执行的 context，在 pool 停止或超时后取消
+func (p *Pool) taskContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	stop := context.AfterFunc(p.ctx, cancel)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		return ctx, func() {
+			cancelTimeout()
+			stop()
+			cancel()
+		}
+	}
+	return ctx, func() {
+		stop()
+		cancel()
 	}
 }
 
+func (p *Pool) submit(ctx context.Context, t taskItem) error {
+	t.submitted = time.Now()
+	var err error
+	if p.queue != nil {
+		err = p.enqueue(t)
+	} else {
+		var w *goWorker
+		if w, err = p.getWorker(ctx); err == nil {
+			w.task <- t
+		}
+	}
+	if p.observer != nil {
+		p.observer.OnSubmit(p.name, err)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSubmitTimeout):
+			p.stats.timedOut.Add(1)
+			if p.timeoutLog {
+				p.warnf("submit worker task timeout")
+			}
+		case errors.Is(err, ErrQueueFull):
+			p.stats.rejected.Add(1)
+		}
+		return err
+	}
+	p.stats.submitted.Add(1)
+	p.stats.priority(t.priority).submitted.Add(1)
+	return nil
+}
+
+// Resize 动态调整最大协程数量，缩容时多余的协程在空闲后退出
+func (p *Pool) Resize(capacity int) {
+	capacity = normalizeCapacity(capacity)
+	p.lock.Lock()
+	p.capacity = capacity
+	var stopped []*goWorker
+	for n := len(p.idle); n > 0 && p.running-len(stopped) > capacity; n-- {
+		stopped = append(stopped, p.idle[n-1])
+		p.idle[n-1] = nil
+		p.idle = p.idle[:n-1]
+	}
+	p.running -= len(stopped)
+	// 扩容后为队列中的任务创建新的协程
+	for p.queue != nil && p.queue.len() > 0 && p.running < capacity {
+		p.acquireWorker().task <- p.queue.pop()
+	}
+	p.notify()
+	p.lock.Unlock()
+	for _, w := range stopped {
+		w.task <- taskItem{}
+	}
+}
+
+// Cap 最大协程数量
+func (p *Pool) Cap() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.capacity
+}
+
+// Running 当前协程数量，包括空闲的协程
+func (p *Pool) Running() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.running
+}
+
+// Release 停止接收新任务和定时任务，取消所有 CtxTask 的 context，等待正在执行和队列中的任务完成，可以重复调用
 func (p *Pool) Release() {
-	close(p.quit)
-	// 等待所有任务执行完成
-	p.wg.Wait()
-	p.log.Printf("release")
+	p.ReleaseCtx(context.Background())
+}
+
+// ReleaseCtx 停止接收新任务和定时任务，在 ctx 结束前等待正在执行和队列中的任务完成，CtxTask 可以继续执行。
+// ctx 结束时取消所有 CtxTask 的 context，丢弃队列中还没有开始执行的任务，不再等待，返回未完成的任务数量。
+// ctx 不会结束时与 Release 相同，立即取消 CtxTask 的 context，避免等待 ctx.Done 的任务使停止一直阻塞
+func (p *Pool) ReleaseCtx(ctx context.Context) int {
+	p.shutdown()
+	if ctx.Done() == nil {
+		p.cancel()
+	}
+	select {
+	case <-p.done:
+		return 0
+	case <-ctx.Done():
+	}
+	p.cancel()
+	unfinished := p.discardQueue()
+	unfinished += int(p.stats.running.Load())
+	p.warnf("release timeout, %d tasks unfinished", unfinished)
+	return unfinished
+}
+
+// ReleaseNow 停止接收新任务，立即取消所有 CtxTask 的 context，丢弃队列中还没有开始执行的任务并返回丢弃的数量。
+// 不等待正在执行的任务，需要等待时可以再调用 Release
+func (p *Pool) ReleaseNow() int {
+	p.shutdown()
+	p.cancel()
+	return p.discardQueue()
+}
+
+// shutdown 停止接收新任务，回收空闲协程，所有协程退出后关闭 done
+func (p *Pool) shutdown() {
+	p.releaseOnce.Do(func() {
+		p.lock.Lock()
+		close(p.quit)
+		idle := p.idle
+		p.idle = nil
+		p.running -= len(idle)
+		sched := p.sched
+		p.lock.Unlock()
+		if sched != nil {
+			sched.stop()
+		}
+		for _, w := range idle {
+			w.task <- taskItem{}
+		}
+		go func() {
+			// 等待所有任务执行完成
+			p.wg.Wait()
+			p.cancel()
+			p.log.Printf("release")
+			close(p.done)
+		}()
+	})
+}
+
+// discardQueue 丢弃队列中的任务，返回丢弃的数量
+func (p *Pool) discardQueue() int {
+	if p.queue == nil {
+		return 0
+	}
+	p.lock.Lock()
+	var items []taskItem
+	for p.queue.len() > 0 {
+		items = append(items, p.queue.pop())
+	}
+	p.lock.Unlock()
+	for _, t := range items {
+		p.discard(t)
+	}
+	return len(items)
 }
@@ -1,6 +1,9 @@
 package worker
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -35,3 +38,221 @@ func TestTimeout(t *testing.T) {
 	t.Log("submit end")
 	worker.Release()
 }
+
+func TestReuse(t *testing.T) {
+	worker := New("reuse-worker", WithCapacity(2), WithIdleTimeout(time.Millisecond*100))
+	defer worker.Release()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		if err := worker.Submit(func() {
+			defer wg.Done()
+			time.Sleep(time.Millisecond)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if n := worker.Running(); n != 2 {
+		t.Fatalf("running %d, want 2", n)
+	}
+	// 空闲协程被回收
+	time.Sleep(time.Millisecond * 300)
+	if n := worker.Running(); n != 0 {
+		t.Fatalf("running %d after idle timeout, want 0", n)
+	}
+}
+
+func TestResize(t *testing.T) {
+	worker := New("resize-worker", WithCapacity(1), WithSubmitTimeout(time.Millisecond*50))
+	defer worker.Release()
+	block := make(chan struct{})
+	if err := worker.Submit(func() { <-block }); err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.Submit(func() {}); err != ErrSubmitTimeout {
+		t.Fatalf("err %v, want %v", err, ErrSubmitTimeout)
+	}
+
+	worker.Resize(3)
+	for i := 0; i < 2; i++ {
+		if err := worker.Submit(func() { <-block }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := worker.Running(); n != 3 {
+		t.Fatalf("running %d, want 3", n)
+	}
+
+	worker.Resize(1)
+	close(block)
+	time.Sleep(time.Millisecond * 50)
+	if n := worker.Running(); n != 1 {
+		t.Fatalf("running %d after shrink, want 1", n)
+	}
+	if c := worker.Cap(); c != 1 {
+		t.Fatalf("cap %d, want 1", c)
+	}
+}
+
+func BenchmarkPool(b *testing.B) {
+	worker := New("bench-worker", WithCapacity(100), WithSubmitTimeout(time.Second))
+	defer worker.Release()
+	var wg sync.WaitGroup
+	task := func() {
+		time.Sleep(time.Microsecond)
+		wg.Done()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		_ = worker.Submit(task)
+	}
+	wg.Wait()
+}
+
+// BenchmarkGoroutinePerTask 每个任务启动一个协程，作为对比
+func BenchmarkGoroutinePerTask(b *testing.B) {
+	active := make(chan struct{}, 100)
+	var wg sync.WaitGroup
+	task := func() {
+		time.Sleep(time.Microsecond)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		active <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				wg.Done()
+				<-active
+			}()
+			task()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSubmitCtx(t *testing.T) {
+	worker := New("ctx-worker", WithCapacity(1), WithSubmitTimeout(time.Millisecond*10))
+	defer worker.Release()
+	block := make(chan struct{})
+	if err := worker.Submit(func() { <-block }); err != nil {
+		t.Fatal(err)
+	}
+	// ctx 设置了 deadline，等待时间不受 submitTimeout 限制
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	time.AfterFunc(time.Millisecond*50, func() { close(block) })
+	if err := worker.SubmitCtx(ctx, func(ctx context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	block = make(chan struct{})
+	defer close(block)
+	if err := worker.Submit(func() { <-block }); err != nil {
+		t.Fatal(err)
+	}
+	// ctx 没有设置 deadline，提前取消时停止等待
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*5, cancel)
+	if err := worker.SubmitCtx(ctx, func(ctx context.Context) {}); err != context.Canceled {
+		t.Fatalf("err %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTaskContext(t *testing.T) {
+	worker := New("task-ctx-worker", WithCapacity(2), WithTaskTimeout(time.Millisecond*20))
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+
+	timeout := make(chan error, 1)
+	_ = worker.SubmitCtx(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+		timeout <- ctx.Err()
+	})
+	released := make(chan any, 1)
+	_ = worker.SubmitCtxTimeout(ctx, 0, func(ctx context.Context) {
+		<-ctx.Done()
+		released <- ctx.Value(key{})
+	})
+	// 提交时的 ctx 取消不影响任务执行
+	cancel()
+	if err := <-timeout; err != context.DeadlineExceeded {
+		t.Fatalf("err %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case <-released:
+		t.Fatal("task cancelled before release")
+	case <-time.After(time.Millisecond * 20):
+	}
+	worker.Release()
+	if v := <-released; v != "v" {
+		t.Fatalf("value %v, want v", v)
+	}
+}
+
+func TestReleaseCtxTimeout(t *testing.T) {
+	worker := New("release-ctx-worker", WithCapacity(1))
+	released := make(chan struct{})
+	_ = worker.SubmitCtx(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(released)
+	})
+	// ctx 结束前任务的 context 不会取消，超时后取消
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if n := worker.ReleaseCtx(ctx); n != 1 {
+		t.Fatalf("unfinished %d, want 1", n)
+	}
+	<-released
+}
+
+func TestRelease(t *testing.T) {
+	worker := New("release-worker", WithCapacity(1), WithQueue(10, RejectAbort))
+	block := make(chan struct{})
+	defer close(block)
+	var ran atomic.Int32
+	_ = worker.Submit(func() {
+		<-block
+		ran.Add(1)
+	})
+	for i := 0; i < 3; i++ {
+		_ = worker.Submit(func() { ran.Add(1) })
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if n := worker.ReleaseCtx(ctx); n != 4 {
+		t.Fatalf("unfinished %d, want 4", n)
+	}
+	// 重复调用
+	if n := worker.ReleaseCtx(ctx); n != 1 {
+		t.Fatalf("unfinished %d, want 1", n)
+	}
+	if err := worker.Submit(func() {}); err != ErrWorkerReleased {
+		t.Fatalf("err %v, want %v", err, ErrWorkerReleased)
+	}
+	if s := worker.Stats(); s.Queued != 0 || s.Rejected != 3 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestReleaseNow(t *testing.T) {
+	worker := New("release-now-worker", WithCapacity(1), WithQueue(10, RejectAbort))
+	cancelled := make(chan struct{})
+	_ = worker.SubmitCtx(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	_ = worker.Submit(func() {})
+	_ = worker.Submit(func() {})
+	if n := worker.ReleaseNow(); n != 2 {
+		t.Fatalf("discarded %d, want 2", n)
+	}
+	<-cancelled
+	if n := worker.ReleaseCtx(context.Background()); n != 0 {
+		t.Fatalf("unfinished %d, want 0", n)
+	}
+}
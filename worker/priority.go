@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrPriorityNoQueue = errors.New("worker priority requires a task queue, use WithQueue")
+
+// Priority 任务优先级，值越大越先执行，可以使用预定义之外的值
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0 // Submit 提交的任务使用 PriorityNormal
+	PriorityHigh   Priority = 1
+)
+
+// SubmitWithPriority 按优先级提交任务，没有空闲协程时优先级高的任务先执行。
+// 队列中的任务每等待 WithAging 设置的时间优先级提升一级，避免低优先级任务一直得不到执行。
+// 必须通过 WithQueue 开启队列，没有队列时等待协程的提交不区分优先级，直接返回 ErrPriorityNoQueue
+func (p *Pool) SubmitWithPriority(prio Priority, t Task) error {
+	if p.queue == nil {
+		return ErrPriorityNoQueue
+	}
+	return p.submit(context.Background(), taskItem{fn: t, priority: prio})
+}
@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubmitWithPriority(t *testing.T) {
+	worker := New("priority-worker", WithCapacity(1), WithQueue(10, RejectAbort), WithAging(time.Hour))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	var (
+		mu    sync.Mutex
+		order []Priority
+	)
+	for _, prio := range []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityLow, PriorityHigh} {
+		p := prio
+		if err := worker.SubmitWithPriority(p, func() {
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(block)
+	worker.Release()
+	want := []Priority{PriorityHigh, PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order %v, want %v", order, want)
+		}
+	}
+
+	s := worker.Stats()
+	if ps := s.Priorities[PriorityHigh]; ps.Submitted != 2 || ps.Completed != 2 || ps.QueueWait.Count != 2 {
+		t.Fatalf("unexpected high priority stats %+v", ps)
+	}
+	if ps := s.Priorities[PriorityNormal]; ps.Submitted != 2 || ps.Completed != 2 {
+		t.Fatalf("unexpected normal priority stats %+v", ps)
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	worker := New("aging-worker", WithCapacity(1), WithQueue(10, RejectAbort), WithAging(time.Millisecond*10))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	var (
+		mu    sync.Mutex
+		order []Priority
+	)
+	submit := func(p Priority) {
+		_ = worker.SubmitWithPriority(p, func() {
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		})
+	}
+	submit(PriorityLow)
+	// 等待超过两个 aging 周期，低优先级任务已经提升到高于 PriorityHigh
+	time.Sleep(time.Millisecond * 50)
+	submit(PriorityHigh)
+	close(block)
+	worker.Release()
+	if len(order) != 2 || order[0] != PriorityLow {
+		t.Fatalf("order %v, want low priority first", order)
+	}
+}
+
+func TestDiscardLowestPriority(t *testing.T) {
+	worker := New("discard-priority-worker", WithCapacity(1), WithQueue(2, RejectDiscardOldest), WithAging(time.Hour))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+	submit := func(name string, p Priority) {
+		_ = worker.SubmitWithPriority(p, func() {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+		})
+	}
+	submit("high", PriorityHigh)
+	submit("low", PriorityLow)
+	submit("normal", PriorityNormal)
+	close(block)
+	worker.Release()
+	if len(ran) != 2 || ran[0] != "high" || ran[1] != "normal" {
+		t.Fatalf("ran %v, want [high normal]", ran)
+	}
+}
+
+func TestSubmitWithPriorityNoQueue(t *testing.T) {
+	worker := New("priority-no-queue-worker", WithCapacity(1))
+	defer worker.Release()
+	// 没有队列时无法按优先级执行，直接返回错误
+	if err := worker.SubmitWithPriority(PriorityHigh, func() {}); err != ErrPriorityNoQueue {
+		t.Fatalf("err %v, want %v", err, ErrPriorityNoQueue)
+	}
+	if s := worker.Stats(); s.Submitted != 0 {
+		t.Fatalf("submitted %d, want 0", s.Submitted)
+	}
+}
@@ -0,0 +1,168 @@
+package worker
+
+import "time"
+
+// RejectPolicy 任务队列满时的拒绝策略
+type RejectPolicy int
+
+const (
+	// RejectAbort 返回 ErrQueueFull
+	RejectAbort RejectPolicy = iota
+	// RejectCallerRuns 在提交任务的协程中直接执行
+	RejectCallerRuns
+	// RejectDiscard 丢弃新提交的任务
+	RejectDiscard
+	// RejectDiscardOldest 丢弃队列中优先级最低的任务中最早提交的一个，再把新任务加入队列
+	RejectDiscardOldest
+)
+
+// taskQueue 固定长度的优先级队列，优先级相同时先进先出。
+// 任务每等待 aging 时间优先级提升一级，等价于按 提交时间 - 优先级*aging 排序，排序依据不随时间变化，可以直接用堆实现
+type taskQueue struct {
+	items    []taskItem
+	capacity int
+	aging    int64
+	seq      uint64
+}
+
+func newTaskQueue(capacity int, aging time.Duration) *taskQueue {
+	return &taskQueue{
+		items:    make([]taskItem, 0, capacity),
+		capacity: capacity,
+		aging:    int64(aging),
+	}
+}
+
+func (q *taskQueue) len() int {
+	return len(q.items)
+}
+
+func (q *taskQueue) full() bool {
+	return len(q.items) >= q.capacity
+}
+
+func (q *taskQueue) score(t *taskItem) int64 {
+	return t.submitted.UnixNano() - int64(t.priority)*q.aging
+}
+
+func (q *taskQueue) less(i, j int) bool {
+	a, b := &q.items[i], &q.items[j]
+	sa, sb := q.score(a), q.score(b)
+	if sa != sb {
+		return sa < sb
+	}
+	return a.seq < b.seq
+}
+
+func (q *taskQueue) push(t taskItem) {
+	q.seq++
+	t.seq = q.seq
+	q.items = append(q.items, t)
+	q.up(len(q.items) - 1)
+}
+
+// pop 取出下一个要执行的任务
+func (q *taskQueue) pop() taskItem {
+	return q.remove(0)
+}
+
+// popOldest 取出优先级最低的任务中最早提交的一个
+func (q *taskQueue) popOldest() taskItem {
+	idx := 0
+	for i := 1; i < len(q.items); i++ {
+		a, b := &q.items[i], &q.items[idx]
+		if a.priority < b.priority || (a.priority == b.priority && a.seq < b.seq) {
+			idx = i
+		}
+	}
+	return q.remove(idx)
+}
+
+func (q *taskQueue) remove(i int) taskItem {
+	n := len(q.items) - 1
+	t := q.items[i]
+	if i != n {
+		q.items[i] = q.items[n]
+	}
+	q.items[n] = taskItem{}
+	q.items = q.items[:n]
+	if i < n {
+		q.down(i)
+		q.up(i)
+	}
+	return t
+}
+
+func (q *taskQueue) up(j int) {
+	for j > 0 {
+		i := (j - 1) / 2
+		if !q.less(j, i) {
+			break
+		}
+		q.items[i], q.items[j] = q.items[j], q.items[i]
+		j = i
+	}
+}
+
+func (q *taskQueue) down(i int) {
+	n := len(q.items)
+	for {
+		j := 2*i + 1
+		if j >= n {
+			break
+		}
+		if r := j + 1; r < n && q.less(r, j) {
+			j = r
+		}
+		if !q.less(j, i) {
+			break
+		}
+		q.items[i], q.items[j] = q.items[j], q.items[i]
+		i = j
+	}
+}
+
+// enqueue 有可用协程时直接执行，否则加入队列，队列满时按拒绝策略处理
+func (p *Pool) enqueue(t taskItem) error {
+	p.lock.Lock()
+	if p.released() {
+		p.lock.Unlock()
+		return ErrWorkerReleased
+	}
+	if w := p.acquireWorker(); w != nil {
+		p.lock.Unlock()
+		w.task <- t
+		return nil
+	}
+	if !p.queue.full() {
+		p.queue.push(t)
+		p.lock.Unlock()
+		return nil
+	}
+	switch p.rejectPolicy {
+	case RejectCallerRuns:
+		p.lock.Unlock()
+		p.doTask(t)
+		return nil
+	case RejectDiscard:
+		p.lock.Unlock()
+		p.discard(t)
+		return nil
+	case RejectDiscardOldest:
+		oldest := p.queue.popOldest()
+		p.queue.push(t)
+		p.lock.Unlock()
+		p.discard(oldest)
+		return nil
+	default:
+		p.lock.Unlock()
+		return ErrQueueFull
+	}
+}
+
+func (p *Pool) discard(t taskItem) {
+	p.stats.rejected.Add(1)
+	if t.onDiscard != nil {
+		t.onDiscard()
+	}
+}
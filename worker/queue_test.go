@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	worker := New("queue-worker", WithCapacity(1), WithQueue(2, RejectAbort))
+	block := make(chan struct{})
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	for i := 0; i < 3; i++ {
+		n := i
+		err := worker.Submit(func() {
+			<-block
+			mu.Lock()
+			order = append(order, n)
+			mu.Unlock()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := worker.Submit(func() {}); err != ErrQueueFull {
+		t.Fatalf("err %v, want %v", err, ErrQueueFull)
+	}
+	s := worker.Stats()
+	if s.Queued != 2 || s.QueueSize != 2 || s.Rejected != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	close(block)
+	worker.Release()
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("order %v, want [0 1 2]", order)
+	}
+}
+
+func TestRejectPolicy(t *testing.T) {
+	run := func(policy RejectPolicy) (ran []int, callerRuns bool) {
+		worker := New("reject-worker", WithCapacity(1), WithQueue(1, policy))
+		block := make(chan struct{})
+		var mu sync.Mutex
+		_ = worker.Submit(func() { <-block })
+		for i := 1; i <= 2; i++ {
+			n := i
+			err := worker.Submit(func() {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, n)
+				if n == 2 && !callerRuns {
+					select {
+					case <-block:
+					default:
+						callerRuns = true
+					}
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		close(block)
+		worker.Release()
+		return ran, callerRuns
+	}
+
+	ran, callerRuns := run(RejectCallerRuns)
+	if len(ran) != 2 || ran[0] != 2 || !callerRuns {
+		t.Fatalf("caller runs: %v %v", ran, callerRuns)
+	}
+	ran, _ = run(RejectDiscard)
+	if len(ran) != 1 || ran[0] != 1 {
+		t.Fatalf("discard: %v", ran)
+	}
+	ran, _ = run(RejectDiscardOldest)
+	if len(ran) != 1 || ran[0] != 2 {
+		t.Fatalf("discard oldest: %v", ran)
+	}
+}
+
+func TestQueueResize(t *testing.T) {
+	worker := New("queue-resize-worker", WithCapacity(1), WithQueue(10, RejectAbort))
+	defer worker.Release()
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		_ = worker.Submit(func() {
+			started <- struct{}{}
+			<-block
+		})
+	}
+	<-started
+	worker.Resize(3)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("queued task not started after resize")
+		}
+	}
+}
+
+func TestQueueShrink(t *testing.T) {
+	worker := New("queue-shrink-worker", WithCapacity(16), WithQueue(100, RejectAbort))
+	defer worker.Release()
+	block := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		if err := worker.Submit(func() {
+			defer wg.Done()
+			<-block
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 缩容后只有多余的协程退出，剩下的协程继续执行队列中的任务
+	worker.Resize(2)
+	close(block)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queued tasks stranded after shrink")
+	}
+	if n := worker.Running(); n != 2 {
+		t.Fatalf("running %d after shrink, want 2", n)
+	}
+}
+
+func TestNextTaskExit(t *testing.T) {
+	worker := New("next-task-worker", WithCapacity(1), WithQueue(10, RejectAbort))
+	defer worker.Release()
+	worker.lock.Lock()
+	worker.running = 3
+	worker.queue.push(taskItem{fn: func() {}})
+	worker.lock.Unlock()
+
+	// 超出容量的协程退出时立即减少 running，最后一个协程继续执行队列中的任务
+	for i := 0; i < 2; i++ {
+		if _, ok := worker.nextTask(&goWorker{pool: worker}); ok {
+			t.Fatalf("worker %d should exit", i)
+		}
+	}
+	next, ok := worker.nextTask(&goWorker{pool: worker})
+	if !ok || next.fn == nil {
+		t.Fatal("queued task stranded")
+	}
+	if n := worker.Running(); n != 1 {
+		t.Fatalf("running %d, want 1", n)
+	}
+}
+
+func TestFutureDiscarded(t *testing.T) {
+	worker := New("future-discard-worker", WithCapacity(1), WithQueue(1, RejectDiscardOldest))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	f1, _ := SubmitFunc(worker, func() (int, error) { return 1, nil })
+	f2, _ := SubmitFunc(worker, func() (int, error) { return 2, nil })
+	if _, err := f1.Get(context.Background()); err != ErrTaskDiscarded {
+		t.Fatalf("err %v, want %v", err, ErrTaskDiscarded)
+	}
+	close(block)
+	if v, err := f2.Get(context.Background()); err != nil || v != 2 {
+		t.Fatalf("got %d, %v", v, err)
+	}
+	worker.Release()
+}
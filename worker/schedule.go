@@ -0,0 +1,259 @@
+package worker
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrInvalidPeriod = errors.New("worker schedule period must be positive")
+
+// ScheduledTask 定时任务句柄，可以用来取消任务
+type ScheduledTask struct {
+	sched     *scheduler
+	task      Task
+	next      time.Time     // 下一次执行时间
+	period    time.Duration // 执行周期，0 表示只执行一次
+	index     int           // 在堆中的位置，-1 表示不在堆中
+	running   atomic.Bool   // 上一次执行是否还没有完成，周期任务不会并发执行
+	cancelled atomic.Bool
+	errMu     sync.Mutex
+	err       error // 最近一次提交到 Pool 失败的原因
+}
+
+// Cancel 取消任务，已经提交到 Pool 但还没有开始执行的任务不再执行，返回 false 表示一次性任务已经到期或任务已经取消
+func (st *ScheduledTask) Cancel() bool {
+	if st.cancelled.Swap(true) {
+		return false
+	}
+	s := st.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st.index < 0 {
+		return false
+	}
+	heap.Remove(&s.tasks, st.index)
+	return true
+}
+
+// Cancelled 是否已经取消
+func (st *ScheduledTask) Cancelled() bool {
+	return st.cancelled.Load()
+}
+
+// Err 返回最近一次到期后提交到 Pool 失败的原因，例如 ErrSubmitTimeout、ErrQueueFull、ErrTaskDiscarded。
+// 一次性任务提交失败后不会再执行，可以通过 Err 判断任务是否执行
+func (st *ScheduledTask) Err() error {
+	st.errMu.Lock()
+	defer st.errMu.Unlock()
+	return st.err
+}
+
+type scheduleHeap []*ScheduledTask
+
+func (h scheduleHeap) Len() int           { return len(h) }
+func (h scheduleHeap) Less(i, j int) bool { return h[i].next.Before(h[j].next) }
+func (h scheduleHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *scheduleHeap) Push(x any) {
+	st := x.(*ScheduledTask)
+	st.index = len(*h)
+	*h = append(*h, st)
+}
+
+func (h *scheduleHeap) Pop() any {
+	old := *h
+	n := len(old)
+	st := old[n-1]
+	old[n-1] = nil
+	st.index = -1
+	*h = old[:n-1]
+	return st
+}
+
+// scheduler 用最小堆保存所有定时任务，一个协程等待最早到期的任务，到期后提交到 Pool 执行
+type scheduler struct {
+	pool    *Pool
+	mu      sync.Mutex
+	tasks   scheduleHeap
+	wake    chan struct{} // 有更早到期的任务时唤醒
+	quit    chan struct{}
+	stopped bool // 已经停止，不再接收新任务
+}
+
+func newScheduler(p *Pool) *scheduler {
+	s := &scheduler{
+		pool: p,
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// add 添加任务，调度器已经停止时取消任务并返回 ErrWorkerReleased
+func (s *scheduler) add(st *ScheduledTask) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		st.cancelled.Store(true)
+		return ErrWorkerReleased
+	}
+	heap.Push(&s.tasks, st)
+	first := st.index == 0
+	s.mu.Unlock()
+	if first {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *scheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		now := time.Now()
+		var due []*ScheduledTask
+		s.mu.Lock()
+		for len(s.tasks) > 0 && !s.tasks[0].next.After(now) {
+			st := s.tasks[0]
+			due = append(due, st)
+			if st.period > 0 {
+				// 固定频率执行，错过的周期直接跳过
+				for !st.next.After(now) {
+					st.next = st.next.Add(st.period)
+				}
+				heap.Fix(&s.tasks, 0)
+			} else {
+				heap.Pop(&s.tasks)
+			}
+		}
+		wait := time.Hour
+		if len(s.tasks) > 0 {
+			wait = s.tasks[0].next.Sub(now)
+		}
+		s.mu.Unlock()
+
+		for _, st := range due {
+			s.fire(st)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// fire 提交到 Pool 执行，上一次执行还没有完成时跳过。
+// 在单独的协程中提交，等待空闲协程或 RejectCallerRuns 执行任务时不会阻塞调度协程
+func (s *scheduler) fire(st *ScheduledTask) {
+	if st.cancelled.Load() || !st.running.CompareAndSwap(false, true) {
+		return
+	}
+	go s.submit(st)
+}
+
+func (s *scheduler) submit(st *ScheduledTask) {
+	err := s.pool.submit(context.Background(), taskItem{
+		fn: func() {
+			defer st.running.Store(false)
+			if !st.cancelled.Load() {
+				st.task()
+			}
+		},
+		onDiscard: func() {
+			s.fail(st, ErrTaskDiscarded)
+		},
+	})
+	if err != nil {
+		s.fail(st, err)
+	}
+}
+
+// fail 记录提交失败，周期任务在下一个周期继续提交
+func (s *scheduler) fail(st *ScheduledTask, err error) {
+	st.errMu.Lock()
+	st.err = err
+	st.errMu.Unlock()
+	st.running.Store(false)
+	s.pool.stats.scheduleFailed.Add(1)
+	s.pool.warnf("submit scheduled task failed: %v", err)
+}
+
+// stop 停止调度，取消所有定时任务
+func (s *scheduler) stop() {
+	close(s.quit)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for _, st := range s.tasks {
+		st.cancelled.Store(true)
+		st.index = -1
+	}
+	s.tasks = nil
+}
+
+// scheduler 返回 Pool 的调度器，第一次使用时创建
+func (p *Pool) scheduler() (*scheduler, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.released() {
+		return nil, ErrWorkerReleased
+	}
+	if p.sched == nil {
+		p.sched = newScheduler(p)
+	}
+	return p.sched, nil
+}
+
+// Schedule 延迟 delay 后在 Pool 的协程中执行任务
+func (p *Pool) Schedule(delay time.Duration, t Task) (*ScheduledTask, error) {
+	return p.schedule(delay, 0, t)
+}
+
+// ScheduleAtFixedRate 延迟 initial 后在 Pool 的协程中执行任务，之后每隔 period 执行一次。
+// 上一次执行还没有完成或者错过的周期会被跳过，同一个任务不会并发执行
+func (p *Pool) ScheduleAtFixedRate(initial, period time.Duration, t Task) (*ScheduledTask, error) {
+	if period <= 0 {
+		return nil, ErrInvalidPeriod
+	}
+	return p.schedule(initial, period, t)
+}
+
+func (p *Pool) schedule(delay, period time.Duration, t Task) (*ScheduledTask, error) {
+	s, err := p.scheduler()
+	if err != nil {
+		return nil, err
+	}
+	st := &ScheduledTask{
+		sched:  s,
+		task:   t,
+		next:   time.Now().Add(delay),
+		period: period,
+		index:  -1,
+	}
+	if err = s.add(st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
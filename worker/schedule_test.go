@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	worker := New("schedule-worker", WithCapacity(2))
+	defer worker.Release()
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	done := make(chan struct{})
+	start := time.Now()
+	for _, n := range []int{30, 10, 20} {
+		delay := n
+		_, err := worker.Schedule(time.Millisecond*time.Duration(delay), func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, delay)
+			if len(order) == 3 {
+				close(done)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancelled, _ := worker.Schedule(time.Millisecond*20, func() {
+		t.Error("cancelled task executed")
+	})
+	if !cancelled.Cancel() || cancelled.Cancel() {
+		t.Fatal("cancel should succeed only once")
+	}
+
+	<-done
+	if cost := time.Since(start); cost < time.Millisecond*30 {
+		t.Fatalf("tasks finished too early: %s", cost)
+	}
+	if order[0] != 10 || order[1] != 20 || order[2] != 30 {
+		t.Fatalf("order %v, want [10 20 30]", order)
+	}
+	time.Sleep(time.Millisecond * 20)
+}
+
+func TestScheduleAtFixedRate(t *testing.T) {
+	worker := New("fixed-rate-worker", WithCapacity(2))
+	var count atomic.Int32
+	st, err := worker.ScheduleAtFixedRate(0, time.Millisecond*10, func() {
+		count.Add(1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 55)
+	if !st.Cancel() {
+		t.Fatal("cancel fixed rate task failed")
+	}
+	n := count.Load()
+	if n < 3 || n > 7 {
+		t.Fatalf("executed %d times, want about 6", n)
+	}
+	time.Sleep(time.Millisecond * 30)
+	if count.Load() != n {
+		t.Fatal("task executed after cancel")
+	}
+
+	if _, err = worker.ScheduleAtFixedRate(0, 0, func() {}); err != ErrInvalidPeriod {
+		t.Fatalf("err %v, want %v", err, ErrInvalidPeriod)
+	}
+
+	// 上一次执行没有完成时跳过
+	var running, overlapped atomic.Int32
+	_, _ = worker.ScheduleAtFixedRate(0, time.Millisecond*5, func() {
+		if running.Add(1) > 1 {
+			overlapped.Add(1)
+		}
+		time.Sleep(time.Millisecond * 20)
+		running.Add(-1)
+	})
+	time.Sleep(time.Millisecond * 60)
+
+	// Release 停止所有定时任务
+	st, _ = worker.ScheduleAtFixedRate(time.Millisecond*10, time.Millisecond*10, func() {})
+	worker.Release()
+	if !st.Cancelled() {
+		t.Fatal("schedule not stopped after release")
+	}
+	if overlapped.Load() != 0 {
+		t.Fatal("fixed rate task executed concurrently")
+	}
+	if _, err = worker.Schedule(0, func() {}); err != ErrWorkerReleased {
+		t.Fatalf("err %v, want %v", err, ErrWorkerReleased)
+	}
+}
+
+func TestScheduleCallerRuns(t *testing.T) {
+	worker := New("schedule-caller-runs-worker", WithCapacity(1), WithQueue(1, RejectCallerRuns), WithLogger(&testLogger{}))
+	defer worker.Release()
+	block := make(chan struct{})
+	defer close(block)
+	_ = worker.Submit(func() { <-block })
+	_ = worker.Submit(func() {})
+
+	// Pool 已满时任务在提交的协程中执行，不会阻塞后面到期的任务
+	_, _ = worker.Schedule(0, func() { <-block })
+	fired := make(chan struct{})
+	_, _ = worker.Schedule(time.Millisecond*10, func() { close(fired) })
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler blocked by caller runs task")
+	}
+}
+
+func TestScheduleDiscard(t *testing.T) {
+	log := &testLogger{}
+	worker := New("schedule-discard-worker", WithCapacity(1), WithQueue(1, RejectDiscard), WithLogger(log))
+	defer worker.Release()
+	block := make(chan struct{})
+	queued := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	_ = worker.Submit(func() { close(queued) })
+
+	once, _ := worker.Schedule(0, func() { t.Error("discarded task executed") })
+	var count atomic.Int32
+	st, _ := worker.ScheduleAtFixedRate(0, time.Millisecond*10, func() { count.Add(1) })
+	deadline := time.Now().Add(time.Second)
+	for once.Err() == nil || st.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("discarded schedule not reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if once.Err() != ErrTaskDiscarded {
+		t.Fatalf("err %v, want %v", once.Err(), ErrTaskDiscarded)
+	}
+	if s := worker.Stats(); s.ScheduleFailed < 2 {
+		t.Fatalf("schedule failed %d, want at least 2", s.ScheduleFailed)
+	}
+	if !log.logged("submit scheduled task failed") {
+		t.Fatal("schedule failure not logged")
+	}
+
+	// 被丢弃的周期任务在 Pool 空闲后继续执行
+	close(block)
+	<-queued
+	for count.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("fixed rate task not recovered after discard")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	st.Cancel()
+}
+
+func TestScheduleAfterStop(t *testing.T) {
+	worker := New("schedule-stop-worker", WithCapacity(1))
+	s, err := worker.scheduler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 取得调度器之后、添加任务之前 Pool 停止，任务不会留在已经停止的调度器中
+	worker.Release()
+	st := &ScheduledTask{sched: s, task: func() {}, next: time.Now(), index: -1}
+	if err = s.add(st); err != ErrWorkerReleased {
+		t.Fatalf("err %v, want %v", err, ErrWorkerReleased)
+	}
+	if !st.Cancelled() {
+		t.Fatal("task not cancelled")
+	}
+}
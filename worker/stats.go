@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBuckets 直方图默认的桶上界
+var DefaultBuckets = []time.Duration{
+	time.Microsecond * 100,
+	time.Millisecond,
+	time.Millisecond * 5,
+	time.Millisecond * 10,
+	time.Millisecond * 50,
+	time.Millisecond * 100,
+	time.Millisecond * 500,
+	time.Second,
+	time.Second * 5,
+}
+
+// Histogram 耗时分布
+type Histogram struct {
+	Buckets []time.Duration // 桶上界，最后一个桶之后的都计入 Counts 的最后一项
+	Counts  []uint64        // 每个桶的数量，比 Buckets 多一项
+	Count   uint64          // 总数
+	Sum     time.Duration   // 总耗时
+}
+
+// Mean 平均耗时
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+type histogram struct {
+	buckets []time.Duration
+	counts  []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+}
+
+func newHistogram(buckets []time.Duration) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]atomic.Uint64, len(buckets)+1),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(h.buckets) && d > h.buckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	s := Histogram{
+		Buckets: h.buckets,
+		Counts:  make([]uint64, len(h.counts)),
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// Stats pool 运行状态
+type Stats struct {
+	Name           string    // pool 名称
+	Capacity       int       // 最大协程数量
+	Workers        int       // 当前协程数量，包括空闲的协程
+	Idle           int       // 空闲协程数量
+	Running        int       // 正在执行的任务数量
+	Waiting        int       // 等待空闲协程的提交数量
+	Queued         int       // 队列中的任务数量
+	QueueSize      int       // 队列长度
+	Submitted      uint64    // 提交成功的任务数量
+	Completed      uint64    // 执行完成的任务数量，包括 panic 的任务
+	Panicked       uint64    // panic 的任务数量
+	TimedOut       uint64    // 提交超时的任务数量
+	TaskTimedOut   uint64    // 执行超时的 CtxTask 数量
+	Rejected       uint64    // 队列满时被拒绝或丢弃的任务数量
+	ScheduleFailed uint64    // 定时任务到期后提交失败或被丢弃的次数
+	QueueWait      Histogram // 从提交到开始执行的等待时间
+	Latency        Histogram // 任务执行耗时
+	Priorities     map[Priority]PriorityStats
+}
+
+// PriorityStats 单个优先级的运行状态
+type PriorityStats struct {
+	Submitted uint64    // 提交成功的任务数量
+	Completed uint64    // 执行完成的任务数量
+	QueueWait Histogram // 从提交到开始执行的等待时间
+}
+
+// MetricsObserver 指标观察者，可以用来把指标导出到监控系统，方法会在提交和执行任务的协程中同步调用，不能阻塞
+type MetricsObserver interface {
+	// OnSubmit 任务提交完成，err 不为空表示提交失败
+	OnSubmit(pool string, err error)
+	// OnStart 任务开始执行，wait 为从提交到开始执行的等待时间
+	OnStart(pool string, wait time.Duration)
+	// OnDone 任务执行完成
+	OnDone(pool string, latency time.Duration, panicked bool)
+}
+
+type poolStats struct {
+	running        atomic.Int64
+	submitted      atomic.Uint64
+	completed      atomic.Uint64
+	panicked       atomic.Uint64
+	timedOut       atomic.Uint64
+	taskTimedOut   atomic.Uint64
+	rejected       atomic.Uint64
+	scheduleFailed atomic.Uint64
+	queueWait      *histogram
+	latency        *histogram
+
+	priorityLock sync.RWMutex
+	priorities   map[Priority]*priorityStats
+}
+
+type priorityStats struct {
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	queueWait *histogram
+}
+
+// priority 返回优先级对应的统计，不存在时创建
+func (s *poolStats) priority(prio Priority) *priorityStats {
+	s.priorityLock.RLock()
+	ps, ok := s.priorities[prio]
+	s.priorityLock.RUnlock()
+	if ok {
+		return ps
+	}
+	s.priorityLock.Lock()
+	defer s.priorityLock.Unlock()
+	if ps, ok = s.priorities[prio]; !ok {
+		ps = &priorityStats{queueWait: newHistogram(DefaultBuckets)}
+		s.priorities[prio] = ps
+	}
+	return ps
+}
+
+func newPoolStats() *poolStats {
+	return &poolStats{
+		queueWait: newHistogram(DefaultBuckets),
+		latency:   newHistogram(DefaultBuckets),
+
+		priorities: make(map[Priority]*priorityStats),
+	}
+}
+
+// Stats 返回 pool 当前的运行状态
+func (p *Pool) Stats() Stats {
+	p.lock.Lock()
+	s := Stats{
+		Name:     p.name,
+		Capacity: p.capacity,
+		Workers:  p.running,
+		Idle:     len(p.idle),
+		Waiting:  p.waiting,
+	}
+	if p.queue != nil {
+		s.Queued = p.queue.len()
+		s.QueueSize = p.queue.capacity
+	}
+	p.lock.Unlock()
+	s.Running = int(p.stats.running.Load())
+	s.Submitted = p.stats.submitted.Load()
+	s.Completed = p.stats.completed.Load()
+	s.Panicked = p.stats.panicked.Load()
+	s.TimedOut = p.stats.timedOut.Load()
+	s.TaskTimedOut = p.stats.taskTimedOut.Load()
+	s.Rejected = p.stats.rejected.Load()
+	s.ScheduleFailed = p.stats.scheduleFailed.Load()
+	s.QueueWait = p.stats.queueWait.snapshot()
+	s.Latency = p.stats.latency.snapshot()
+	p.stats.priorityLock.RLock()
+	s.Priorities = make(map[Priority]PriorityStats, len(p.stats.priorities))
+	for prio, ps := range p.stats.priorities {
+		s.Priorities[prio] = PriorityStats{
+			Submitted: ps.submitted.Load(),
+			Completed: ps.completed.Load(),
+			QueueWait: ps.queueWait.snapshot(),
+		}
+	}
+	p.stats.priorityLock.RUnlock()
+	return s
+}
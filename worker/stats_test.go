@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testObserver struct {
+	mu       sync.Mutex
+	submit   int
+	failed   int
+	start    int
+	done     int
+	panicked int
+}
+
+func (o *testObserver) OnSubmit(pool string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.submit++
+	if err != nil {
+		o.failed++
+	}
+}
+
+func (o *testObserver) OnStart(pool string, wait time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.start++
+}
+
+func (o *testObserver) OnDone(pool string, latency time.Duration, panicked bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done++
+	if panicked {
+		o.panicked++
+	}
+}
+
+func TestStats(t *testing.T) {
+	observer := &testObserver{}
+	worker := New("stats-worker", WithCapacity(2), WithSubmitTimeout(time.Millisecond*10),
+		WithTaskTimeout(time.Millisecond*5), WithMetricsObserver(observer))
+	block := make(chan struct{})
+	_ = worker.Submit(func() { <-block })
+	_ = worker.SubmitCtx(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		<-block
+	})
+	if err := worker.Submit(func() {}); err != ErrSubmitTimeout {
+		t.Fatalf("err %v, want %v", err, ErrSubmitTimeout)
+	}
+	s := worker.Stats()
+	if s.Name != "stats-worker" || s.Capacity != 2 || s.Running != 2 || s.Submitted != 2 || s.TimedOut != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	close(block)
+	_ = worker.Submit(func() { panic("boom") })
+	worker.Release()
+
+	s = worker.Stats()
+	if s.Running != 0 || s.Submitted != 3 || s.Completed != 3 || s.Panicked != 1 || s.TaskTimedOut != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.Latency.Count != 3 || s.QueueWait.Count != 3 || s.Latency.Mean() <= 0 {
+		t.Fatalf("unexpected histogram %+v %+v", s.Latency, s.QueueWait)
+	}
+	var total uint64
+	for _, c := range s.Latency.Counts {
+		total += c
+	}
+	if total != 3 {
+		t.Fatalf("bucket total %d, want 3", total)
+	}
+	if observer.submit != 4 || observer.failed != 1 || observer.start != 3 || observer.done != 3 || observer.panicked != 1 {
+		t.Fatalf("unexpected observer %+v", observer)
+	}
+}